@@ -9,7 +9,7 @@ set(CMAKE_TOOLCHAIN_FILE "{{TOOLCHAIN_FILE}}")
 set(CMAKE_MODULE_PATH "${CMAKE_CURRENT_SOURCE_DIR}")
 
 # Hardware
-include("${PROJECT_PATH}/.wio/targets/{{TARGET_NAME}}/hardware.cmake")
+include("${CMAKE_CURRENT_SOURCE_DIR}/hardware.cmake")
 
 # C++ standard
 set(CMAKE_CXX_STANDARD {{CPP_STANDARD}})
@@ -28,13 +28,16 @@ set(FRAMEWORK {{FRAMEWORK}})
 set(BOARD ${WIO_TARGET_HARDWARE})
 set(ENTRY {{ENTRY}})
 
+# Build type
+set(CMAKE_BUILD_TYPE "{{BUILD_TYPE}}")
+
 # CMAKE
 cmake_minimum_required(VERSION ${CMAKE_VERSION})
 project(${PROJECT_NAME} C CXX ASM)
 cmake_policy(SET CMP0023 OLD)
 
 # Dependencies
-set(DEPENDENCY_FILE "${PROJECT_PATH}/.wio/targets/${TARGET_NAME}/dependencies.cmake")
+set(DEPENDENCY_FILE "${CMAKE_CURRENT_SOURCE_DIR}/dependencies.cmake")
 
 file(GLOB_RECURSE SRC_FILES
     "${PROJECT_PATH}/${ENTRY}/*.cpp"
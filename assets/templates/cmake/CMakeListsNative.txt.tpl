@@ -8,7 +8,7 @@ set(PROJECT_PATH "{{PROJECT_PATH}}")
 set(CMAKE_MODULE_PATH ${CMAKE_CURRENT_SOURCE_DIR})
 
 # Hardware
-include("${PROJECT_PATH}/.wio/targets/{{TARGET_NAME}}/hardware.cmake")
+include("${CMAKE_CURRENT_SOURCE_DIR}/hardware.cmake")
 
 # C++ standard
 set(CMAKE_CXX_STANDARD {{CPP_STANDARD}})
@@ -27,12 +27,15 @@ set(PLATFORM {{PLATFORM}})
 set(FRAMEWORK {{FRAMEWORK}})
 set(ENTRY {{ENTRY}})
 
+# Build type
+set(CMAKE_BUILD_TYPE "{{BUILD_TYPE}}")
+
 # CMAKE
 cmake_minimum_required(VERSION ${CMAKE_VERSION})
 project(${PROJECT_NAME} C CXX ASM)
 
 # Dependencies
-set(DEPENDENCY_FILE "${PROJECT_PATH}/.wio/targets/${TARGET_NAME}/dependencies.cmake")
+set(DEPENDENCY_FILE "${CMAKE_CURRENT_SOURCE_DIR}/dependencies.cmake")
 
 file(GLOB_RECURSE ${TARGET_NAME}_FILES
     ${PROJECT_PATH}/${ENTRY}/*.cpp
@@ -14,14 +14,20 @@ import (
 	"wio/internal/executor"
 
 	"wio/internal/cmd"
+	"wio/internal/cmd/config"
 	"wio/internal/cmd/create"
 	"wio/internal/cmd/devices"
+	"wio/internal/cmd/doctor"
+	"wio/internal/cmd/lint"
 	"wio/internal/cmd/pac/install"
 	"wio/internal/cmd/pac/publish"
+	"wio/internal/cmd/pac/search"
 	"wio/internal/cmd/pac/user"
 	"wio/internal/cmd/pac/vendor"
 	"wio/internal/cmd/run"
+	"wio/internal/cmd/toolchain"
 	"wio/internal/cmd/upgrade"
+	"wio/internal/cmd/validate"
 	"wio/internal/config/defaults"
 	"wio/internal/config/meta"
 	"wio/internal/constants"
@@ -91,6 +97,14 @@ var updateFlags = []cli.Flag{
 		Name:  "full",
 		Usage: "Full update and overrides files.",
 	},
+	cli.BoolFlag{
+		Name:  "migrate",
+		Usage: "Migrates wio.yml to the latest schema and shows the changes before writing them.",
+	},
+	cli.BoolFlag{
+		Name:  "yes",
+		Usage: "Writes the migrated wio.yml without asking for confirmation.",
+	},
 }
 
 var installFlags = []cli.Flag{
@@ -106,9 +120,144 @@ var installFlags = []cli.Flag{
 		Name:  "options",
 		Usage: "Options to use while downloading from url.",
 	},
+	cli.StringFlag{
+		Name:  "path",
+		Usage: "Directory of a package to use in place.",
+	},
+	cli.BoolFlag{
+		Name:  "update",
+		Usage: "Fetch git dependencies again instead of using the commits in wio.lock.",
+	},
+}
+
+var configShowFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "resolved",
+		Usage: "Show configuration after targets are merged with what they extend.",
+	},
+}
+
+var configGetFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "resolved",
+		Usage: "Get value after targets, conditions and variables are resolved.",
+	},
+	cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print value as json.",
+	},
+}
+
+var packFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "list",
+		Usage: "Only list files that would be in the package.",
+	},
+}
+
+var searchFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "limit",
+		Usage: "Maximum number of packages to show.",
+		Value: 20,
+	},
+	cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print packages as json.",
+	},
+}
+
+var otpFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "otp",
+		Usage: "One-time password for accounts with two-factor authentication.",
+	},
+}
+
+var loginFlags = append([]cli.Flag{
+	cli.BoolFlag{
+		Name: "token",
+		Usage: "Login with a token created on the registry instead of a username and password. The token is " +
+			"read from stdin, like echo $TOKEN | wio login --token, or asked for without echoing it.",
+	},
+}, otpFlags...)
+
+var publishFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "workspace",
+		Usage: "Publish workspace members whose version is not published yet.",
+	},
+	cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Do everything except sending the package to the registry.",
+	},
+	cli.BoolFlag{
+		Name:  "no-lint",
+		Usage: "Publish without linting and building the package first.",
+	},
+	cli.StringFlag{
+		Name:  "tag",
+		Usage: "Dist-tag to point to the published version.",
+		Value: "latest",
+	},
+}
+
+var unpublishFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "yes",
+		Usage: "Unpublish without asking for confirmation.",
+	},
+}
+
+var versionFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "preid",
+		Usage: "Prerelease id for prerelease bumps: e.g. 'beta' for 1.0.1-beta.0.",
+	},
+	cli.BoolFlag{
+		Name:  "no-git",
+		Usage: "Only change wio.yml without committing and tagging the version. Otherwise tracked files cannot have uncommitted changes, untracked files are ignored.",
+	},
+	cli.StringFlag{
+		Name:  "message",
+		Usage: "Message of the version commit and tag, %s is replaced by the version.",
+		Value: "v%s",
+	},
+}
+
+var toolchainInstallFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "retool",
+		Usage: "Removes existing toolchain and downloads it again.",
+	},
+}
+
+var toolchainPruneFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "yes",
+		Usage: "Remove unused toolchains without asking for confirmation.",
+	},
+}
+
+var lintFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "no-build",
+		Usage: "Skip building every target of the package.",
+	},
+}
+
+var validateFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "schema",
+		Usage: "Print JSON Schema of wio.yml instead of validating it.",
+	},
 }
 
 var buildFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "profile",
+		Usage: "Build profile to use, e.g. debug, release or minsize.",
+	},
 	cli.BoolFlag{
 		Name:  "force",
 		Usage: "Forces a full build for targets.",
@@ -121,9 +270,17 @@ var buildFlags = []cli.Flag{
 		Name:  "all",
 		Usage: "Build all available targets.",
 	},
+	cli.BoolFlag{
+		Name:  "workspace",
+		Usage: "Build all members of the workspace in dependency order.",
+	},
 }
 
 var cleanFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "profile",
+		Usage: "Build profile to use, e.g. debug, release or minsize.",
+	},
 	cli.BoolFlag{
 		Name:  "all",
 		Usage: "Clean all available targets.",
@@ -135,6 +292,10 @@ var cleanFlags = []cli.Flag{
 }
 
 var runFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "profile",
+		Usage: "Build profile to use, e.g. debug, release or minsize.",
+	},
 	cli.StringFlag{
 		Name:  "port",
 		Usage: "Specify upload port.",
@@ -142,7 +303,19 @@ var runFlags = []cli.Flag{
 	},
 	cli.StringFlag{
 		Name:  "args",
-		Usage: "Arguments passed to executable.",
+		Usage: "Shell quoted arguments passed to executable, overrides run args in wio.yml.",
+	},
+	cli.BoolFlag{
+		Name:  "simulate",
+		Usage: "Run AVR firmware in a simulator instead of uploading it.",
+	},
+	cli.StringFlag{
+		Name:  "simulator",
+		Usage: "Simulator command where {{MCU}}, {{FREQUENCY}} and {{FIRMWARE}} are replaced.",
+	},
+	cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "Stop the simulation after the given duration, e.g. 30s.",
 	},
 }
 
@@ -159,6 +332,11 @@ var envFlags = []cli.Flag{
 		Usage: "Creates and updates local environment."},
 }
 
+var doctorFlags = []cli.Flag{
+	cli.StringFlag{Name: "dir",
+		Usage: "Project directory whose target platforms are checked, all platforms outside of a project."},
+}
+
 var upgradeFlags = []cli.Flag{
 	cli.BoolFlag{Name: "force",
 		Usage: "Overrides all the restrictions and forces an update."},
@@ -200,6 +378,49 @@ var commands = []cli.Command{
 			command = create.Create{Context: c, Update: true}
 		},
 	},
+	{
+		Name:      "config",
+		Usage:     "Inspect and edit the project configuration.",
+		UsageText: "wio config <subcommand> [command options]",
+		Subcommands: cli.Commands{
+			cli.Command{
+				Name:      "show",
+				Usage:     "Shows the wio.yml configuration.",
+				UsageText: "wio config show [command options]",
+				Flags:     append(configShowFlags, appWideFlags...),
+				Action: func(c *cli.Context) {
+					command = config.Config{Context: c, Command: config.SHOW}
+				},
+			},
+			cli.Command{
+				Name:      "get",
+				Usage:     "Prints a value from wio.yml, e.g. targets.main.board.",
+				UsageText: "wio config get <path> [command options]",
+				Flags:     append(configGetFlags, appWideFlags...),
+				Action: func(c *cli.Context) {
+					command = config.Config{Context: c, Command: config.GET}
+				},
+			},
+			cli.Command{
+				Name:      "set",
+				Usage:     "Sets a value in wio.yml, e.g. targets.main.board uno.",
+				UsageText: "wio config set <path> <value> [command options]",
+				Flags:     appWideFlags,
+				Action: func(c *cli.Context) {
+					command = config.Config{Context: c, Command: config.SET}
+				},
+			},
+		},
+	},
+	{
+		Name:      "validate",
+		Usage:     "Checks wio.yml for mistakes.",
+		UsageText: "wio validate [command options]",
+		Flags:     append(validateFlags, appWideFlags...),
+		Action: func(c *cli.Context) {
+			command = validate.Validate{Context: c}
+		},
+	},
 	{
 		Name:      "build",
 		Usage:     "Configure and build the project.",
@@ -264,8 +485,8 @@ var commands = []cli.Command{
 	{
 		Name:      "login",
 		Usage:     "Login to the registry.",
-		UsageText: "wio login",
-		Flags:     appWideFlags,
+		UsageText: "wio login [command options]",
+		Flags:     append(loginFlags, appWideFlags...),
 		Action: func(c *cli.Context) {
 			command = user.Login{Context: c}
 		},
@@ -279,15 +500,155 @@ var commands = []cli.Command{
 			command = user.Logout{Context: c}
 		},
 	},
+	{
+		Name:      "whoami",
+		Usage:     "Print the user logged in to the registry.",
+		UsageText: "wio whoami",
+		Flags:     appWideFlags,
+		Action: func(c *cli.Context) {
+			command = user.Whoami{Context: c}
+		},
+	},
+	{
+		Name:      "search",
+		Usage:     "Search the registry for packages.",
+		UsageText: "wio search [command options] <terms...>",
+		Flags:     append(searchFlags, appWideFlags...),
+		Action: func(c *cli.Context) {
+			command = search.Cmd{Context: c}
+		},
+	},
+	{
+		Name:      "version",
+		Usage:     "Bump the version of the package and tag it in git.",
+		UsageText: "wio version <major|minor|patch|prerelease|version> [command options]",
+		Flags:     append(versionFlags, appWideFlags...),
+		Action: func(c *cli.Context) {
+			command = publish.Version{Context: c}
+		},
+	},
+	{
+		Name:      "lint",
+		Usage:     "Check that a package is ready to be published.",
+		UsageText: "wio lint [command options]",
+		Flags:     append(lintFlags, appWideFlags...),
+		Action: func(c *cli.Context) {
+			command = lint.Lint{Context: c}
+		},
+	},
+	{
+		Name:      "pack",
+		Usage:     "Create the package tarball that would be published.",
+		UsageText: "wio pack [command options]",
+		Flags:     append(packFlags, appWideFlags...),
+		Action: func(c *cli.Context) {
+			command = publish.Pack{Context: c}
+		},
+	},
 	{
 		Name:      "publish",
 		Usage:     "Publish package to registry.",
-		UsageText: "wio publish",
-		Flags:     appWideFlags,
+		UsageText: "wio publish [command options]",
+		Flags:     append(append(publishFlags, otpFlags...), appWideFlags...),
 		Action: func(c *cli.Context) {
 			command = publish.Cmd{Context: c}
 		},
 	},
+	{
+		Name:      "dist-tag",
+		Usage:     "Manage tags pointing to published versions of a package.",
+		UsageText: "wio dist-tag <subcommand> [command options]",
+		Subcommands: cli.Commands{
+			cli.Command{
+				Name:      "add",
+				Usage:     "Points a tag to a version of a package.",
+				UsageText: "wio dist-tag add <package>@<version> <tag>",
+				Flags:     append(otpFlags, appWideFlags...),
+				Action: func(c *cli.Context) {
+					command = publish.DistTag{Context: c, Command: publish.ADD}
+				},
+			},
+			cli.Command{
+				Name:      "rm",
+				Usage:     "Removes a tag from a package.",
+				UsageText: "wio dist-tag rm <package> <tag>",
+				Flags:     append(otpFlags, appWideFlags...),
+				Action: func(c *cli.Context) {
+					command = publish.DistTag{Context: c, Command: publish.REMOVE}
+				},
+			},
+			cli.Command{
+				Name:      "ls",
+				Usage:     "Lists tags of a package, the package in the current directory by default.",
+				UsageText: "wio dist-tag ls [package]",
+				Flags:     appWideFlags,
+				Action: func(c *cli.Context) {
+					command = publish.DistTag{Context: c, Command: publish.LIST}
+				},
+			},
+		},
+	},
+	{
+		Name:      "deprecate",
+		Usage:     "Deprecates versions of a package, an empty message removes the deprecation.",
+		UsageText: "wio deprecate <package>@<range> <message>",
+		Flags:     append(otpFlags, appWideFlags...),
+		Action: func(c *cli.Context) {
+			command = publish.Deprecate{Context: c}
+		},
+	},
+	{
+		Name:      "unpublish",
+		Usage:     "Removes a published version of a package.",
+		UsageText: "wio unpublish <package>@<version> [command options]",
+		Flags:     append(append(unpublishFlags, otpFlags...), appWideFlags...),
+		Action: func(c *cli.Context) {
+			command = publish.Unpublish{Context: c}
+		},
+	},
+	{
+		Name:      "toolchain",
+		Usage:     "Manages toolchains downloaded for building projects. WIOTOOLCHAINMIRROR sets a registry, git host or directory of archives to download them from.",
+		UsageText: "wio toolchain <subcommand> [command options]",
+		Subcommands: cli.Commands{
+			cli.Command{
+				Name:      "list",
+				Usage:     "Lists installed toolchains with their versions and disk usage.",
+				UsageText: "wio toolchain list",
+				Flags:     appWideFlags,
+				Action: func(c *cli.Context) {
+					command = toolchain.Toolchain{Context: c, Command: toolchain.LIST}
+				},
+			},
+			cli.Command{
+				Name:      "install",
+				Usage:     "Downloads a toolchain ahead of building.",
+				UsageText: "wio toolchain install <toolchain> [command options]",
+				Flags:     append(toolchainInstallFlags, appWideFlags...),
+				Action: func(c *cli.Context) {
+					command = toolchain.Toolchain{Context: c, Command: toolchain.INSTALL}
+				},
+			},
+			cli.Command{
+				Name:      "remove",
+				Usage:     "Removes a toolchain, every version of it when none is given.",
+				UsageText: "wio toolchain remove <toolchain>[:<ref>]",
+				Flags:     appWideFlags,
+				Action: func(c *cli.Context) {
+					command = toolchain.Toolchain{Context: c, Command: toolchain.REMOVE}
+				},
+			},
+			cli.Command{
+				Name:      "prune",
+				Usage:     "Removes toolchains not used by the projects, the current directory by default.",
+				UsageText: "wio toolchain prune [project directories...] [command options]",
+				Flags:     append(toolchainPruneFlags, appWideFlags...),
+				Action: func(c *cli.Context) {
+					command = toolchain.Toolchain{Context: c, Command: toolchain.PRUNE}
+				},
+			},
+		},
+	},
 	{
 		Name:      "devices",
 		Usage:     "Handles serial devices connected.",
@@ -359,6 +720,15 @@ var commands = []cli.Command{
 			},
 		},
 	},
+	{
+		Name:      "doctor",
+		Usage:     "Checks tools, environment, serial port access and registry, with a fix for each problem.",
+		UsageText: "wio doctor [command options]",
+		Flags:     append(doctorFlags, appWideFlags...),
+		Action: func(c *cli.Context) {
+			command = doctor.Doctor{Context: c}
+		},
+	},
 	{
 		Name:      "upgrade",
 		Usage:     "Upgrades wio to a specific version or latest version.",
@@ -424,6 +794,9 @@ func main() {
 	errorHandle := func(err error) {
 		if err != nil {
 			log.Errln(err.Error())
+			if exitErr, ok := err.(cmd.ExitError); ok {
+				os.Exit(exitErr.ExitCode())
+			}
 			os.Exit(1)
 		}
 	}
@@ -27,4 +27,5 @@ require (
 	golang.org/x/crypto v0.0.0-20190211182817-74369b46fc67
 	gopkg.in/src-d/go-git.v4 v4.10.0
 	gopkg.in/yaml.v2 v2.2.2
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,176 @@
+// Part of config package, which contains all the commands to inspect and edit wio.yml
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const (
+	SHOW = 0
+	GET  = 1
+	SET  = 2
+)
+
+type Config struct {
+	Context *cli.Context
+	Command byte
+}
+
+// get context for the command
+func (config Config) GetContext() *cli.Context {
+	return config.Context
+}
+
+// Runs the config command
+func (config Config) Execute() error {
+	directory, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	switch config.Command {
+	case SHOW:
+		return showConfig(directory, config.Context.Bool("resolved"))
+	case GET:
+		if len(config.Context.Args()) != 1 {
+			return util.Error("wio config get needs a path like targets.main.board")
+		}
+		return getConfig(directory, config.Context.Args()[0], config.Context.Bool("resolved"),
+			config.Context.Bool("json"))
+	case SET:
+		if len(config.Context.Args()) != 2 {
+			return util.Error("wio config set needs a path like targets.main.board and a value")
+		}
+		return setConfig(directory, config.Context.Args()[0], config.Context.Args()[1])
+	}
+	return nil
+}
+
+// Prints wio.yml as it is written or as wio sees it after targets are resolved
+func showConfig(directory string, resolved bool) error {
+	if !resolved {
+		if _, err := types.ReadWioConfig(directory, false); err != nil {
+			return err
+		}
+		data, err := sys.NormalIO.ReadFile(sys.Path(directory, sys.Config))
+		if err != nil {
+			return err
+		}
+		log.Info("%s", string(data))
+		return nil
+	}
+
+	projectConfig, err := types.ReadWioConfig(directory, true)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(projectConfig)
+	if err != nil {
+		return err
+	}
+	log.Info("%s", string(data))
+	return nil
+}
+
+func splitPath(path string) ([]string, error) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys {
+		if key == "" {
+			return nil, util.Error("invalid path %s", path)
+		}
+	}
+	return keys, nil
+}
+
+// Prints value at a dotted path of wio.yml. Values are printed as they are and maps and lists
+// are printed as yaml or json
+func getConfig(directory string, path string, resolved bool, asJson bool) error {
+	keys, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+	document, err := types.ReadWioDocument(directory, resolved)
+	if err != nil {
+		return err
+	}
+	node := types.FindNode(document, keys...)
+	if node == nil {
+		return util.Error("%s does not exist in %s", path, sys.Config)
+	}
+
+	if asJson {
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		log.Info("%s\n", string(data))
+		return nil
+	}
+
+	if node.Kind == yamlv3.ScalarNode {
+		log.Info("%s\n", node.Value)
+		return nil
+	}
+	data, err := types.EncodeNode(node)
+	if err != nil {
+		return err
+	}
+	log.Info("%s", string(data))
+	return nil
+}
+
+// Sets value at a dotted path of wio.yml. Value is read as yaml so lists and maps can be set and
+// the change is only written when it does not make wio.yml invalid
+func setConfig(directory string, path string, value string) error {
+	keys, err := splitPath(path)
+	if err != nil {
+		return err
+	}
+	node := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal([]byte(value), node); err != nil {
+		return util.Error("invalid value %s: %s", value, err.Error())
+	}
+	if node.Kind == yamlv3.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	} else {
+		node = &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: value}
+	}
+
+	editor, err := types.EditWioConfig(directory)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, problem := range editor.Check() {
+		existing[problem.Message] = true
+	}
+
+	if err := editor.SetNode(node, keys...); err != nil {
+		return err
+	}
+	problems := 0
+	for _, problem := range editor.Check() {
+		if !existing[problem.Message] {
+			log.Errln("%s:%s", sys.Config, problem.Error())
+			problems++
+		}
+	}
+	if problems > 0 {
+		return util.Error("%s was not changed since %s would be invalid", sys.Config, path)
+	}
+	return editor.Save()
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"wio/internal/types"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const testConfig = `type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.10.0
+    default_target: main
+targets:
+  main:
+    src: src
+    platform: avr
+    board: uno
+`
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		path string
+		keys []string
+		err  string
+	}{
+		{"type", []string{"type"}, ""},
+		{"targets.main.board", []string{"targets", "main", "board"}, ""},
+		{"", nil, "invalid path "},
+		{"targets..board", nil, "invalid path targets..board"},
+		{"targets.main.", nil, "invalid path targets.main."},
+		{".targets", nil, "invalid path .targets"},
+	}
+	for _, test := range tests {
+		keys, err := splitPath(test.path)
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, test.path)
+		} else {
+			assert.NoError(t, err, test.path)
+		}
+		assert.Equal(t, test.keys, keys, test.path)
+	}
+}
+
+func TestGetConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(sys.Path(dir, sys.Config), []byte(testConfig), 0644))
+
+	tests := []struct {
+		path   string
+		asJson bool
+		err    string
+	}{
+		{"targets.main.board", false, ""},
+		{"targets.main", false, ""},
+		{"targets.main", true, ""},
+		{"targets.main.port", false, "targets.main.port does not exist in wio.yml"},
+		{"targets.main.board.name", false, "targets.main.board.name does not exist in wio.yml"},
+		{"targets..board", false, "invalid path targets..board"},
+	}
+	for _, test := range tests {
+		err := getConfig(dir, test.path, false, test.asJson)
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, test.path)
+		} else {
+			assert.NoError(t, err, test.path)
+		}
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := sys.Path(dir, sys.Config)
+
+	tests := []struct {
+		path  string
+		value string
+		kind  yamlv3.Kind
+		found string
+		err   string
+	}{
+		{"targets.main.board", "mega2560", yamlv3.ScalarNode, "mega2560", ""},
+		{"targets.main.linker_flags", "[-lm, -lc]", yamlv3.SequenceNode, "", ""},
+		{"targets.tests.src", "tests", yamlv3.ScalarNode, "tests", ""},
+		{"targets..board", "uno", 0, "", "invalid path targets..board"},
+		{"targets.main.compiler", "gcc", 0, "", "wio.yml was not changed since targets.main.compiler would be invalid"},
+	}
+	for _, test := range tests {
+		assert.NoError(t, ioutil.WriteFile(path, []byte(testConfig), 0644))
+		err := setConfig(dir, test.path, test.value)
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, test.path)
+			data, err := ioutil.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, testConfig, string(data), test.path)
+			continue
+		}
+		assert.NoError(t, err, test.path)
+
+		keys, err := splitPath(test.path)
+		assert.NoError(t, err)
+		document, err := types.ReadWioDocument(dir, false)
+		assert.NoError(t, err)
+		node := types.FindNode(document, keys...)
+		if assert.NotNil(t, node, test.path) {
+			assert.Equal(t, test.kind, node.Kind, test.path)
+			assert.Equal(t, test.found, node.Value, test.path)
+		}
+	}
+}
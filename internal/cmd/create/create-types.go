@@ -51,6 +51,9 @@ func (create Create) Execute() error {
 		if err := performWioExistsCheck(directory); err != nil {
 			return err
 		}
+		if create.Context.Bool("migrate") {
+			return migrateConfig(directory, create.Context.Bool("yes"))
+		}
 		if info, err = create.handleUpdate(directory); err != nil {
 			return err
 		}
@@ -81,6 +81,10 @@ func (create Create) handleUpdate(directory string) (*createInfo, error) {
 		configOnly: create.Context.Bool("only-config"),
 	}
 
+	if types.NeedsMigration(cfg) {
+		log.Warnln("wio.yml uses an older schema, run `wio update --migrate` to migrate it")
+	}
+
 	newUpdateVer := create.Context.String("version")
 	if !util.IsEmptyString(newUpdateVer) {
 		cfg.SetVersion(newUpdateVer)
@@ -116,7 +120,7 @@ func updatePackageConfig(queue *log.Queue, config types.Config, info *createInfo
 	if config.GetName() != filepath.Base(info.directory) {
 		log.Warnln(queue, "Base directory different from project name")
 	}
-	return types.WriteWioConfig(info.directory, config)
+	return writeVersion(info, config)
 }
 
 func updateAppConfig(queue *log.Queue, config types.Config, info *createInfo) error {
@@ -127,7 +131,22 @@ func updateAppConfig(queue *log.Queue, config types.Config, info *createInfo) er
 	if config.GetName() != filepath.Base(info.directory) {
 		log.Warnln(queue, "Base directory different from project name")
 	}
-	return types.WriteWioConfig(info.directory, config)
+	return writeVersion(info, config)
+}
+
+// Changes version of the project in wio.yml when a new version is provided
+func writeVersion(info *createInfo, config types.Config) error {
+	if util.IsEmptyString(info.context.String("version")) {
+		return nil
+	}
+	editor, err := types.EditWioConfig(info.directory)
+	if err != nil {
+		return err
+	}
+	if err := editor.Set(config.GetVersion(), "project", "version"); err != nil {
+		return err
+	}
+	return editor.Save()
 }
 
 // Update project files
@@ -149,3 +168,41 @@ func updateProjectFiles(queue *log.Queue, info *createInfo) error {
 	copyProjectAssets(queue, info, dataType, info.fullUpdate)
 	return nil
 }
+
+// Migrates wio.yml to the latest schema after showing the changes that will be made. The
+// changes are written without asking when confirmed is set
+func migrateConfig(directory string, confirmed bool) error {
+	text, migrated, applied, err := types.MigrateWioConfig(directory)
+	if err != nil {
+		return err
+	}
+	if len(applied) <= 0 {
+		log.Infoln(log.Green, "wio.yml is already up to date")
+		return nil
+	}
+
+	log.Infoln(log.Cyan, "migrations to apply:")
+	for _, migration := range applied {
+		log.Infoln("  %s: %s", migration.Version, migration.Description)
+	}
+	log.Writeln()
+	log.Info("%s", util.UnifiedDiff("wio.yml", "wio.yml (migrated)", string(text), string(migrated)))
+	log.Writeln()
+
+	if !confirmed {
+		if yes, err := log.PromptYes("write migrated wio.yml?"); err != nil {
+			return err
+		} else if !yes {
+			log.Infoln(log.Yellow, "wio.yml was not changed")
+			return nil
+		}
+	}
+
+	log.Info(log.Cyan, "migrating wio.yml ... ")
+	if err := types.WriteWioText(directory, migrated); err != nil {
+		log.WriteFailure()
+		return err
+	}
+	log.WriteSuccess()
+	return nil
+}
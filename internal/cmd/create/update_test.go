@@ -0,0 +1,43 @@
+package create
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateConfigConfirmed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-migrate")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := sys.Path(dir, sys.Config)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.9.0
+    flags:
+    - -DPATH=${PATH}
+`), 0644))
+
+	// confirmed migrations are written without a prompt so that they can run in CI
+	assert.NoError(t, migrateConfig(dir, true))
+	text, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, `type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.10.2
+    flags:
+    - -DPATH=$${PATH}
+`, string(text))
+
+	assert.NoError(t, migrateConfig(dir, true))
+	again, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, text, again)
+}
@@ -0,0 +1,171 @@
+package doctor
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"wio/internal/constants"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+)
+
+var versionNumber = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+// Program a platform needs along with how to install it on each os
+type binary struct {
+	name    string
+	args    []string
+	min     string
+	purpose string
+	// only needed by some commands, like simulating
+	optional bool
+	install  map[string]string
+}
+
+var (
+	cmake = binary{
+		name: "cmake", args: []string{"--version"}, min: "3.1.0", purpose: "generating build files",
+		install: map[string]string{
+			sys.LINUX:   "sudo apt-get install cmake",
+			sys.DARWIN:  "brew install cmake",
+			sys.WINDOWS: "install CMake from https://cmake.org/download and add it to PATH",
+		},
+	}
+	makeBinaries = map[string]binary{
+		"make": {
+			name: "make", args: []string{"--version"}, purpose: "building",
+			install: map[string]string{
+				sys.LINUX:   "sudo apt-get install make",
+				sys.DARWIN:  "xcode-select --install",
+				sys.WINDOWS: "install MinGW and add mingw32-make to PATH, or install Ninja",
+			},
+		},
+		"ninja": {
+			name: "ninja", args: []string{"--version"}, purpose: "building",
+			install: map[string]string{
+				sys.LINUX:   "sudo apt-get install ninja-build",
+				sys.DARWIN:  "brew install ninja",
+				sys.WINDOWS: "download Ninja from https://github.com/ninja-build/ninja/releases and add it to PATH",
+			},
+		},
+		"mingw32-make": {
+			name: "mingw32-make", args: []string{"--version"}, purpose: "building",
+			install: map[string]string{
+				sys.WINDOWS: "install MinGW and add its bin folder to PATH",
+			},
+		},
+		"nmake": {
+			name: "nmake", args: []string{"/?"}, purpose: "building",
+			install: map[string]string{
+				sys.WINDOWS: "run wio from a Visual Studio developer command prompt",
+			},
+		},
+	}
+	nativeCompiler = binary{
+		name: "c++", args: []string{"--version"}, purpose: "building native targets",
+		install: map[string]string{
+			sys.LINUX:  "sudo apt-get install g++",
+			sys.DARWIN: "xcode-select --install",
+		},
+	}
+	mingwCompiler = binary{
+		name: "g++", args: []string{"--version"}, purpose: "building native targets",
+		install: map[string]string{
+			sys.WINDOWS: "install MinGW-w64 and add its bin folder to PATH",
+		},
+	}
+	avrInstall = map[string]string{
+		sys.LINUX:   "sudo apt-get install gcc-avr avr-libc",
+		sys.DARWIN:  "brew tap osx-cross/avr && brew install avr-gcc",
+		sys.WINDOWS: "install the AVR 8-bit toolchain from Microchip and add its bin folder to PATH",
+	}
+	avrBinaries = []binary{
+		{name: "avr-gcc", args: []string{"--version"}, min: "4.8.0", purpose: "building avr targets",
+			install: avrInstall},
+		{name: "avr-g++", args: []string{"--version"}, min: "4.8.0", purpose: "building avr targets",
+			install: avrInstall},
+		{name: "avrdude", args: []string{"-?"}, purpose: "uploading to avr boards",
+			install: map[string]string{
+				sys.LINUX:   "sudo apt-get install avrdude",
+				sys.DARWIN:  "brew install avrdude",
+				sys.WINDOWS: "install avrdude and add it to PATH",
+			}},
+		{name: "simavr", args: []string{"--help"}, purpose: "simulating avr targets", optional: true,
+			install: map[string]string{
+				sys.LINUX:   "sudo apt-get install simavr",
+				sys.DARWIN:  "brew tap osx-cross/avr && brew install simavr",
+				sys.WINDOWS: "simavr does not run on Windows, simulate from WSL instead",
+			}},
+	}
+)
+
+// Checks for programs the platforms need. The make program depends on the CMake generator wio picks
+func binaryChecks(platforms map[string]bool) []check {
+	binaries := []binary{cmake, makeBinaries[util.GetMake()]}
+	if platforms[constants.Native] {
+		if sys.GetOS() != sys.WINDOWS {
+			binaries = append(binaries, nativeCompiler)
+		} else if util.GetCmakeGenerator() == "MinGW Makefiles" {
+			binaries = append(binaries, mingwCompiler)
+		}
+	}
+	if platforms[constants.Avr] {
+		binaries = append(binaries, avrBinaries...)
+	}
+
+	var checks []check
+	for _, b := range binaries {
+		b := b
+		checks = append(checks, check{name: b.name, run: b.check})
+	}
+	return checks
+}
+
+func (b binary) check() (string, []problem) {
+	fix := b.install[sys.GetOS()]
+	if fix == "" {
+		fix = fmt.Sprintf("install %s and add it to PATH", b.name)
+	}
+
+	path, err := exec.LookPath(b.name)
+	if err != nil {
+		return "not found", []problem{{
+			message: fmt.Sprintf("%s is needed for %s but was not found in PATH", b.name, b.purpose),
+			fix:     fix,
+			warning: b.optional,
+		}}
+	}
+
+	// some programs exit with an error after printing their version
+	output, _ := exec.Command(path, b.args...).CombinedOutput()
+	return b.checkVersion(path, string(output), fix)
+}
+
+// Reads the version from the output of the program and compares it to the minimum version
+func (b binary) checkVersion(path string, output string, fix string) (string, []problem) {
+	found := versionNumber.FindString(output)
+	if found == "" {
+		return path, nil
+	}
+	if b.min != "" {
+		version, min := semver.Parse(fullVersion(found)), semver.Parse(b.min)
+		if version != nil && min != nil && version.LT(*min) {
+			return found, []problem{{
+				message: fmt.Sprintf("%s %s is too old, at least %s is needed", b.name, found, b.min),
+				fix:     "upgrade: " + fix,
+				warning: b.optional,
+			}}
+		}
+	}
+	return found, nil
+}
+
+// Versions like 3.10 are read as 3.10.0
+func fullVersion(version string) string {
+	if strings.Count(version, ".") < 2 {
+		return version + ".0"
+	}
+	return version
+}
@@ -0,0 +1,60 @@
+package doctor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullVersion(t *testing.T) {
+	assert.Equal(t, "3.10.0", fullVersion("3.10"))
+	assert.Equal(t, "3.10.2", fullVersion("3.10.2"))
+}
+
+func TestVersionNumber(t *testing.T) {
+	outputs := map[string]string{
+		"cmake version 3.10.2\n\nCMake suite maintained by Kitware": "3.10.2",
+		"GNU Make 4.1\nBuilt for x86_64-pc-linux-gnu":               "4.1",
+		"1.8.2\n": "1.8.2",
+		"avr-gcc (GCC) 5.4.0\nCopyright (C) 2015 Free Software Foundation": "5.4.0",
+		"g++ (Ubuntu 7.3.0-27ubuntu1~18.04) 7.3.0\nCopyright (C) 2017":     "7.3.0",
+		"program: unknown option --version":                                "",
+	}
+	for output, version := range outputs {
+		assert.Equal(t, version, versionNumber.FindString(output), output)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	b := binary{name: "cmake", min: "3.1.0", purpose: "generating build files"}
+
+	found, problems := b.checkVersion("/usr/bin/cmake", "cmake version 3.10.2", "fix")
+	assert.Equal(t, "3.10.2", found)
+	assert.Empty(t, problems)
+
+	found, problems = b.checkVersion("/usr/bin/cmake", "cmake version 3.1", "fix")
+	assert.Equal(t, "3.1", found)
+	assert.Empty(t, problems)
+
+	found, problems = b.checkVersion("/usr/bin/cmake", "cmake version 3.0.2", "fix")
+	assert.Equal(t, "3.0.2", found)
+	assert.Equal(t, []problem{{
+		message: "cmake 3.0.2 is too old, at least 3.1.0 is needed",
+		fix:     "upgrade: fix",
+	}}, problems)
+
+	// a version that cannot be read is not a problem
+	found, problems = b.checkVersion("/usr/bin/cmake", "cmake", "fix")
+	assert.Equal(t, "/usr/bin/cmake", found)
+	assert.Empty(t, problems)
+
+	optional := binary{name: "simavr", min: "1.5.0", optional: true}
+	_, problems = optional.checkVersion("/usr/bin/simavr", "1.4", "fix")
+	assert.Len(t, problems, 1)
+	assert.True(t, problems[0].warning)
+
+	noMin := binary{name: "make"}
+	found, problems = noMin.checkVersion("/usr/bin/make", "GNU Make 3.81", "fix")
+	assert.Equal(t, "3.81", found)
+	assert.Empty(t, problems)
+}
@@ -0,0 +1,231 @@
+package doctor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"wio/internal/config/root"
+	"wio/internal/constants"
+	"wio/internal/env"
+	"wio/pkg/npm/client"
+	"wio/pkg/npm/login"
+	"wio/pkg/npm/registry"
+	"wio/pkg/util/sys"
+
+	"github.com/joho/godotenv"
+	"go.bug.st/serial.v1"
+)
+
+// Groups that own serial ports on linux distributions
+var serialGroups = []string{"dialout", "uucp"}
+
+// Checks that the wio root exists and wio can write to it
+func checkRoot() (string, []problem) {
+	return checkRootAt(root.GetWioUserPath())
+}
+
+// Checks the wio root in wioPath without creating anything that is missing
+func checkRootAt(wioPath string) (string, []problem) {
+	dirs := []string{
+		sys.Path(wioPath, constants.RootToolchain),
+		sys.Path(wioPath, constants.Security),
+		sys.Path(wioPath, constants.RootUpdate),
+	}
+
+	for _, dir := range append([]string{wioPath}, dirs...) {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", []problem{{
+				message: fmt.Sprintf("%s does not exist", dir),
+				fix:     fmt.Sprintf("make sure your home directory is writable, wio creates %s when it starts", wioPath),
+			}}
+		}
+	}
+
+	var problems []problem
+	for _, dir := range dirs {
+		file, err := ioutil.TempFile(dir, ".doctor")
+		if err != nil {
+			fix := fmt.Sprintf("sudo chown -R $USER %s", wioPath)
+			if sys.GetOS() == sys.WINDOWS {
+				fix = fmt.Sprintf("give your user write access to %s", wioPath)
+			}
+			problems = append(problems, problem{message: fmt.Sprintf("%s is not writable", dir), fix: fix})
+			continue
+		}
+		file.Close()
+		os.Remove(file.Name())
+	}
+
+	tokens := sys.Path(wioPath, constants.Security, login.TokensFileName)
+	if info, err := os.Stat(tokens); err == nil && sys.GetOS() != sys.WINDOWS && info.Mode().Perm()&0077 != 0 {
+		problems = append(problems, problem{
+			message: "registry tokens can be read by other users",
+			fix:     fmt.Sprintf("chmod 600 %s", tokens),
+		})
+	}
+	return wioPath, problems
+}
+
+// Checks that wio.env matches this wio and that the shell does not override it
+func checkEnv() (string, []problem) {
+	envs, err := godotenv.Read(root.GetEnvFilePath())
+	if err != nil {
+		return "", []problem{{
+			message: fmt.Sprintf("%s could not be read: %s", root.GetEnvFilePath(), err.Error()),
+			fix:     "wio env reset",
+		}}
+	}
+	wioRoot, err := sys.NormalIO.GetRoot()
+	if err != nil {
+		return "", []problem{{message: err.Error(), fix: "reinstall wio"}}
+	}
+	wioPath, err := os.Executable()
+	if err != nil {
+		return "", []problem{{message: err.Error(), fix: "reinstall wio"}}
+	}
+
+	expected := []struct{ name, value string }{
+		{"WIOROOT", wioRoot},
+		{"WIOPATH", wioPath},
+		{"WIOOS", sys.GetOS()},
+		{"WIOARCH", sys.GetArch()},
+	}
+	var problems []problem
+	for _, e := range expected {
+		// shell variables are not overridden when wio.env is loaded
+		if value := os.Getenv(e.name); value != envs[e.name] {
+			problems = append(problems, problem{
+				message: fmt.Sprintf("%s is set to %s in the shell, which overrides %s from wio.env", e.name, value, envs[e.name]),
+				fix:     fmt.Sprintf("unset %s in your shell profile", e.name),
+			})
+		}
+		if envs[e.name] != e.value {
+			problems = append(problems, problem{
+				message: fmt.Sprintf("%s in wio.env is %s but this wio uses %s", e.name, envs[e.name], e.value),
+				fix:     "wio env reset",
+			})
+		}
+	}
+	return root.GetEnvFilePath(), problems
+}
+
+// Checks that serial ports can be opened. Ports on linux belong to the dialout group on most
+// distributions and to uucp on some others
+func checkSerial() (string, []problem) {
+	ports, err := serial.GetPortsList()
+	found := fmt.Sprintf("%d port(s) found", len(ports))
+	if err != nil {
+		found = "ports could not be listed"
+	}
+	if sys.GetOS() != sys.LINUX || os.Geteuid() == 0 {
+		return found, nil
+	}
+
+	groups, err := os.Getgroups()
+	if err != nil {
+		return found, nil
+	}
+	current, err := user.Current()
+	if err != nil {
+		return found, nil
+	}
+	userGroups, _ := current.GroupIds()
+
+	var missing *user.Group
+	for _, name := range serialGroups {
+		group, err := user.LookupGroup(name)
+		if err != nil {
+			continue
+		}
+		gid, err := strconv.Atoi(group.Gid)
+		if err != nil {
+			continue
+		}
+		if containsInt(groups, gid) || os.Getegid() == gid {
+			return found, nil
+		}
+		// added to the group after logging in
+		if containsString(userGroups, group.Gid) {
+			return found, []problem{{
+				message: fmt.Sprintf("%s was added to the %s group but this session does not have it yet", current.Username, name),
+				fix:     "log out and back in",
+			}}
+		}
+		if missing == nil {
+			missing = group
+		}
+	}
+	if missing == nil {
+		return found, nil
+	}
+	return found, []problem{{
+		message: fmt.Sprintf("%s is not in the %s group so serial ports cannot be opened for upload or monitor", current.Username, missing.Name),
+		fix:     fmt.Sprintf("sudo usermod -aG %s $USER, then log out and back in", missing.Name),
+	}}
+}
+
+// Checks that the registry and the toolchain mirror can be reached
+func checkRegistry() (string, []problem) {
+	url := registry.Url()
+	var problems []problem
+	resp, err := client.Npm.Get(client.UrlResolve(url, "-", "ping"))
+	if err != nil {
+		problems = append(problems, problem{
+			message: fmt.Sprintf("registry %s could not be reached: %s", url, err.Error()),
+			fix:     "check your network connection or set another registry with wio env set WIOREGISTRY=<url>",
+		})
+	} else {
+		resp.Body.Close()
+		if resp.StatusCode != 200 {
+			problems = append(problems, problem{
+				message: fmt.Sprintf("registry %s responded with %s", url, resp.Status),
+				fix:     "check that WIOREGISTRY is an npm registry with wio env",
+			})
+		}
+	}
+
+	if mirror := env.GetToolchainMirror(); mirror != "" {
+		fix := "check WIOTOOLCHAINMIRROR with wio env or unset it with wio env unset WIOTOOLCHAINMIRROR"
+		if isUrl(mirror) {
+			if resp, err := client.Npm.Get(mirror); err != nil {
+				problems = append(problems, problem{
+					message: fmt.Sprintf("toolchain mirror %s could not be reached: %s", mirror, err.Error()),
+					fix:     fix,
+				})
+			} else {
+				resp.Body.Close()
+			}
+		} else if !sys.Exists(mirror) {
+			problems = append(problems, problem{
+				message: fmt.Sprintf("toolchain mirror %s does not exist", mirror),
+				fix:     fix,
+			})
+		}
+	}
+	return url, problems
+}
+
+func isUrl(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
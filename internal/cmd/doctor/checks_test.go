@@ -0,0 +1,50 @@
+package doctor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wio/internal/constants"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRootMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-doctor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	wioPath := filepath.Join(dir, constants.WioRoot)
+	found, problems := checkRootAt(wioPath)
+	assert.Equal(t, "", found)
+	assert.Len(t, problems, 1)
+	assert.Equal(t, wioPath+" does not exist", problems[0].message)
+	assert.False(t, sys.Exists(wioPath))
+
+	assert.NoError(t, os.MkdirAll(sys.Path(wioPath, constants.RootToolchain), os.ModePerm))
+	_, problems = checkRootAt(wioPath)
+	assert.Len(t, problems, 1)
+	assert.Equal(t, sys.Path(wioPath, constants.Security)+" does not exist", problems[0].message)
+	assert.False(t, sys.Exists(sys.Path(wioPath, constants.Security)))
+}
+
+func TestCheckRoot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-doctor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	wioPath := filepath.Join(dir, constants.WioRoot)
+	for _, name := range []string{constants.RootToolchain, constants.Security, constants.RootUpdate} {
+		assert.NoError(t, os.MkdirAll(sys.Path(wioPath, name), os.ModePerm))
+	}
+	found, problems := checkRootAt(wioPath)
+	assert.Equal(t, wioPath, found)
+	assert.Empty(t, problems)
+
+	// the writable check leaves nothing behind
+	files, err := ioutil.ReadDir(sys.Path(wioPath, constants.RootToolchain))
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
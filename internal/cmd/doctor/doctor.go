@@ -0,0 +1,106 @@
+// Part of doctor package, which checks that everything wio needs to build and upload is set up
+package doctor
+
+import (
+	"strings"
+	"wio/internal/cmd"
+	"wio/internal/constants"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+
+	"github.com/urfave/cli"
+)
+
+type Doctor struct {
+	Context *cli.Context
+}
+
+// get context for the command
+func (doctor Doctor) GetContext() *cli.Context {
+	return doctor.Context
+}
+
+// Problem found by a check and how to fix it. Warnings are problems only some commands run into
+type problem struct {
+	message string
+	fix     string
+	warning bool
+}
+
+// Check returns what it found when there are no problems
+type check struct {
+	name string
+	run  func() (string, []problem)
+}
+
+// Runs every check and prints problems with a hint to fix each of them
+func (doctor Doctor) Execute() error {
+	checks := binaryChecks(doctor.platforms())
+	checks = append(checks,
+		check{name: "wio root", run: checkRoot},
+		check{name: "wio environment", run: checkEnv},
+		check{name: "serial port access", run: checkSerial},
+		check{name: "registry", run: checkRegistry},
+	)
+
+	errors := 0
+	for _, c := range checks {
+		log.Info(log.Cyan, "Checking %s ... ", c.name)
+		found, problems := c.run()
+
+		failed := false
+		for _, p := range problems {
+			failed = failed || !p.warning
+		}
+		if failed {
+			log.Infoln(log.Red, "failed")
+		} else if len(problems) > 0 {
+			log.Infoln(log.Yellow, "%s", found)
+		} else {
+			log.Infoln(log.Green, "%s", found)
+		}
+
+		for _, p := range problems {
+			if p.warning {
+				log.Warnln("%s", p.message)
+			} else {
+				log.Errln("%s", p.message)
+				errors++
+			}
+			log.Infoln(log.Yellow, "    fix: %s", p.fix)
+		}
+	}
+
+	if errors > 0 {
+		return util.Error("wio doctor found %d problem(s)", errors)
+	}
+	log.Infoln(log.Green, "No problems found")
+	return nil
+}
+
+// Platforms of the targets of the project in the directory, all platforms outside of a project
+func (doctor Doctor) platforms() map[string]bool {
+	all := map[string]bool{constants.Native: true, constants.Avr: true}
+	dir, err := cmd.GetDirectory(doctor)
+	if err != nil || !sys.Exists(sys.Path(dir, sys.Config)) {
+		return all
+	}
+	config, err := types.ReadWioConfig(dir, true)
+	if err != nil {
+		log.Warnln("checking all platforms since wio.yml could not be read: %s", err.Error())
+		return all
+	}
+
+	platforms := map[string]bool{}
+	for _, target := range config.GetTargets() {
+		if platform := strings.ToLower(target.GetPlatform()); all[platform] {
+			platforms[platform] = true
+		}
+	}
+	if len(platforms) <= 0 {
+		return all
+	}
+	return platforms
+}
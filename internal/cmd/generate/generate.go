@@ -22,19 +22,32 @@ var supportedPlatforms = map[string]bool{
 	constants.Native: true,
 }
 
+// File in the build directory of a target with the path of the toolchain it was generated with
+const ToolchainFile = "wio.toolchain"
+
 type InfoGenerate struct {
 	Config types.Config
 
 	Directory   string
 	ProjectType string
 	Port        string
+	Profile     types.Profile
 
 	Retool      bool
 	NoDepCreate bool
 }
 
+// Build directory of the target for the profile being generated
+func TargetPath(info *InfoGenerate, target types.Target) string {
+	profileName := ""
+	if info.Profile != nil {
+		profileName = info.Profile.GetName()
+	}
+	return utils.TargetPath(info.Directory, target.GetName(), profileName)
+}
+
 func HardwareFile(info *InfoGenerate, target types.Target) error {
-	targetPath := sys.Path(utils.BuildPath(info.Directory), target.GetName())
+	targetPath := TargetPath(info, target)
 
 	templateFilePath := sys.Path("templates", "cmake", "hardware.cmake.tpl")
 	hardwareFilePath := sys.Path(targetPath, "hardware.cmake")
@@ -75,11 +88,23 @@ func CMakeListsFile(info *InfoGenerate, target types.Target) error {
 	}
 
 	var toolchainPath string
+	var lock *types.LockImpl
 	var err error
 	if platform != constants.Native {
-		if toolchainPath, err = downloader.DownloadToolchain(target.GetFramework(), info.Retool); err != nil {
+		if lock, err = types.ReadLock(info.Directory); err != nil {
 			return err
 		}
+		if toolchainPath, err = downloader.DownloadToolchain(target.GetFramework(), lock, info.Retool); err != nil {
+			return err
+		}
+		if lock.Changed() {
+			if err := types.WriteLock(info.Directory, lock); err != nil {
+				return err
+			}
+		}
+	}
+	if err := writeToolchainFile(info, target, toolchainPath, lock); err != nil {
+		return err
 	}
 
 	projectName := info.Config.GetName()
@@ -90,11 +115,55 @@ func CMakeListsFile(info *InfoGenerate, target types.Target) error {
 		return err
 	}
 
-	return cmake.GenerateCmakeLists(toolchainPath, target, projectName, projectPath, cppStandard, cStandard)
+	return cmake.GenerateCmakeLists(toolchainPath, target, info.Profile, projectName, projectPath,
+		TargetPath(info, target), cppStandard, cStandard)
+}
+
+// Records the toolchain and toolchain dependencies build files of a target were generated with
+func writeToolchainFile(info *InfoGenerate, target types.Target, toolchainPath string, lock *types.LockImpl) error {
+	targetPath := TargetPath(info, target)
+	if err := os.MkdirAll(targetPath, os.ModePerm); err != nil {
+		return err
+	}
+	paths := []string{toolchainPath}
+	if lock != nil {
+		paths = downloader.ToolchainPaths(toolchainPath, lock)
+	}
+	return sys.NormalIO.WriteFile(sys.Path(targetPath, ToolchainFile), []byte(strings.Join(paths, "\n")))
+}
+
+// Checks whether the toolchain locked for a target or its dependencies are not the ones its build
+// files were generated with. A toolchain that is not locked yet or not installed counts as changed
+func ToolchainChanged(info *InfoGenerate, target types.Target) bool {
+	if strings.ToLower(target.GetPlatform()) == constants.Native {
+		return false
+	}
+	lock, err := types.ReadLock(info.Directory)
+	if err != nil {
+		return true
+	}
+	data, err := sys.NormalIO.ReadFile(sys.Path(TargetPath(info, target), ToolchainFile))
+	if err != nil {
+		return true
+	}
+	locked := downloader.LockedToolchainPath(target.GetFramework(), lock)
+	if locked == "" {
+		return true
+	}
+	paths := downloader.ToolchainPaths(locked, lock)
+	if paths == nil || strings.Join(paths, "\n") != string(data) {
+		return true
+	}
+	for _, path := range paths {
+		if !sys.Exists(path) {
+			return true
+		}
+	}
+	return false
 }
 
 func DependenciesFile(info *InfoGenerate, target types.Target) error {
-	cmakePath := sys.Path(utils.BuildPath(info.Directory), target.GetName())
+	cmakePath := TargetPath(info, target)
 	if err := os.MkdirAll(cmakePath, os.ModePerm); err != nil {
 		return err
 	}
@@ -0,0 +1,53 @@
+// Part of lint package, which checks a package before it is published
+package lint
+
+import (
+	"os"
+	"wio/internal/cmd/run"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/npm/publish"
+	"wio/pkg/util"
+
+	"github.com/urfave/cli"
+)
+
+type Lint struct {
+	Context *cli.Context
+}
+
+// get context for the command
+func (lint Lint) GetContext() *cli.Context {
+	return lint.Context
+}
+
+// Runs the checks publishing does and builds every target of the package
+func (lint Lint) Execute() error {
+	directory, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	config, err := types.ReadWioConfig(directory, true)
+	if err != nil {
+		return err
+	}
+
+	if err := publish.RunLint(directory, config); err != nil {
+		return err
+	}
+
+	if !lint.Context.Bool("no-build") {
+		if err := TrialBuild(lint.Context, directory)(); err != nil {
+			return util.Error("package failed to build: %s", err.Error())
+		}
+	}
+	log.Infoln(log.Green, "%s@%s is ready to be published", config.GetName(), config.GetVersion())
+	return nil
+}
+
+// Returns a function building every target of the package in directory
+func TrialBuild(context *cli.Context, directory string) func() error {
+	return func() error {
+		return run.BuildAll(context, directory)
+	}
+}
@@ -2,12 +2,15 @@ package install
 
 import (
 	"github.com/urfave/cli"
+	"path/filepath"
 	"strings"
 	"wio/internal/cmd"
+	"wio/internal/constants"
 	"wio/internal/types"
 	"wio/pkg/log"
 	"wio/pkg/npm/resolve"
 	"wio/pkg/util"
+	"wio/pkg/util/sys"
 )
 
 type Cmd struct {
@@ -34,7 +37,15 @@ func (c Cmd) Execute() error {
 	}
 	c.info = resolve.NewInfo(c.dir)
 
-	if len(c.Context.Args()) > 0 {
+	if c.Context.Bool("update") {
+		if err := c.updateGitDependencies(); err != nil {
+			return err
+		}
+	} else if !util.IsEmptyString(c.Context.String("path")) {
+		if err := c.AddPathDependency(); err != nil {
+			return err
+		}
+	} else if len(c.Context.Args()) > 0 {
 		if err := c.AddDependency(); err != nil {
 			return err
 		}
@@ -93,6 +104,70 @@ func (c Cmd) AddDependency() error {
 		}
 	}
 
+	return c.writeDependency(name, newDependency)
+}
+
+// Adds a package from a directory on disk, it is used in place instead of being installed
+func (c Cmd) AddPathDependency() error {
+	path := c.Context.String("path")
+	absPath := path
+	if !filepath.IsAbs(absPath) {
+		absPath = sys.Path(c.dir, path)
+	}
+	pkgConfig, err := types.ReadWioConfig(absPath, false)
+	if err != nil {
+		return err
+	}
+	if pkgConfig.GetType() != constants.Pkg {
+		return util.Error("project at %s is not a package", path)
+	}
+
+	name := pkgConfig.GetName()
+	if len(c.Context.Args()) > 0 && c.Context.Args()[0] != name {
+		return util.Error("package at %s is named %s", path, name)
+	}
+
+	log.Info(log.Cyan, "Adding dependency: ")
+	log.Infoln(log.Green, "%s@%s from %s", name, pkgConfig.GetVersion(), path)
+
+	return c.writeDependency(name, &types.DependencyImpl{
+		Version: "^" + pkgConfig.GetVersion(),
+		Path:    filepath.ToSlash(path),
+	})
+}
+
+// Makes git dependencies given as arguments, or all of them, fetch the commit their ref or tag
+// points to now instead of the one in wio.lock
+func (c Cmd) updateGitDependencies() error {
+	names := []string(c.Context.Args())
+	deps := c.config.GetDependencies()
+	if len(names) <= 0 {
+		for name, dep := range deps {
+			if dep != nil && dep.GetGit() != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range names {
+		if dep, exists := deps[name]; !exists || dep == nil {
+			return util.Error("%s is not a dependency", name)
+		} else if dep.GetGit() == "" {
+			return util.Error("%s is not a git dependency", name)
+		}
+	}
+	c.info.SetUpdate(names)
+	return nil
+}
+
+func (c Cmd) writeDependency(name string, newDependency *types.DependencyImpl) error {
 	c.config.AddDependency(name, newDependency)
-	return types.WriteWioConfig(c.dir, c.config)
+
+	editor, err := types.EditWioConfig(c.dir)
+	if err != nil {
+		return err
+	}
+	if err := editor.Set(newDependency, "dependencies", name); err != nil {
+		return err
+	}
+	return editor.Save()
 }
@@ -0,0 +1,83 @@
+package publish
+
+import (
+	"fmt"
+	"strings"
+	"wio/pkg/log"
+	"wio/pkg/npm/login"
+	"wio/pkg/npm/publish"
+	"wio/pkg/npm/registry"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+
+	"github.com/urfave/cli"
+)
+
+type Deprecate struct {
+	Context *cli.Context
+}
+
+type Unpublish struct {
+	Context *cli.Context
+}
+
+func (c Deprecate) GetContext() *cli.Context {
+	return c.Context
+}
+
+func (c Unpublish) GetContext() *cli.Context {
+	return c.Context
+}
+
+// Deprecates versions of a package matching a range. An empty message removes the deprecation
+func (c Deprecate) Execute() error {
+	login.OneTimePassword = c.Context.String("otp")
+	args := c.Context.Args()
+	if len(args) < 2 {
+		return util.Error("expected <package>@<range> <message>")
+	}
+	name, versionQuery := splitSpec(args[0])
+	if versionQuery == "" {
+		versionQuery = "*"
+	}
+	message := strings.Join(args[1:], " ")
+
+	versions, err := publish.Deprecate(registry.Url(), name, versionQuery, message)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if message == "" {
+			log.Infoln(log.Green, "%s@%s is no longer deprecated", name, version)
+		} else {
+			log.Infoln(log.Yellow, "%s@%s is deprecated", name, version)
+		}
+	}
+	return nil
+}
+
+// Removes a published version of a package
+func (c Unpublish) Execute() error {
+	login.OneTimePassword = c.Context.String("otp")
+	args := c.Context.Args()
+	if len(args) != 1 {
+		return util.Error("expected <package>@<version>")
+	}
+	name, version := splitSpec(args[0])
+	if semver.Parse(version) == nil {
+		return util.Error("a single version has to be unpublished, %s is not a version", version)
+	}
+	if !c.Context.Bool("yes") {
+		confirmed, err := log.PromptYes(fmt.Sprintf("Unpublish %s@%s? Nobody will be able to install it", name,
+			version))
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+
+	if err := publish.Unpublish(registry.Url(), name, version); err != nil {
+		return err
+	}
+	log.Infoln(log.Green, "Unpublished %s@%s", name, version)
+	return nil
+}
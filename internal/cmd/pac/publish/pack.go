@@ -0,0 +1,48 @@
+package publish
+
+import (
+	"wio/internal/cmd"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/npm/publish"
+)
+
+// Creates the tarball that publishing would upload and leaves it in the project. With --list
+// only files that would be in the package are listed
+func (c Pack) Execute() error {
+	dir, err := cmd.GetDirectory(c)
+	if err != nil {
+		return err
+	}
+	cfg, err := types.ReadWioConfig(dir, true)
+	if err != nil {
+		return err
+	}
+	if c.Context.Bool("list") {
+		data, err := publish.VersionData(dir, cfg)
+		if err != nil {
+			return err
+		}
+		files, err := publish.SelectFiles(dir, data)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			log.Infoln("%s", file)
+		}
+		return nil
+	}
+
+	log.Info(log.Cyan, "Zipping package .... ")
+	tarball, err := publish.Pack(dir, cfg, dir)
+	if err != nil {
+		log.WriteFailure()
+		return err
+	}
+	log.WriteSuccess()
+
+	publish.LogTarball(tarball)
+	log.Info(log.Cyan, "Created ")
+	log.Infoln(log.Green, "%s", tarball.File)
+	return nil
+}
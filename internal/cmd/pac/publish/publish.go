@@ -2,9 +2,16 @@ package publish
 
 import (
 	"wio/internal/cmd"
+	"wio/internal/cmd/lint"
+	"wio/internal/constants"
 	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/npm/client"
+	"wio/pkg/npm/login"
 	"wio/pkg/npm/publish"
 	"wio/pkg/npm/registry"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
 
 	"github.com/urfave/cli"
 )
@@ -13,18 +20,98 @@ type Cmd struct {
 	Context *cli.Context
 }
 
+type Pack struct {
+	Context *cli.Context
+}
+
 func (c Cmd) GetContext() *cli.Context {
 	return c.Context
 }
 
+func (c Pack) GetContext() *cli.Context {
+	return c.Context
+}
+
 func (c Cmd) Execute() error {
+	login.OneTimePassword = c.Context.String("otp")
 	dir, err := cmd.GetDirectory(c)
 	if err != nil {
 		return err
 	}
+	options := publish.Options{
+		DryRun:   c.Context.Bool("dry-run"),
+		SkipLint: c.Context.Bool("no-lint"),
+		Tag:      c.Context.String("tag"),
+	}
+	if c.Context.Bool("workspace") {
+		return c.publishWorkspace(dir, options)
+	}
 	cfg, err := types.ReadWioConfig(dir, true)
 	if err != nil {
 		return err
 	}
-	return publish.Do(dir, registry.WioPackageRegistry, cfg)
+	options.TrialBuild = lint.TrialBuild(c.Context, dir)
+	return publish.Do(dir, registry.Url(), cfg, options)
+}
+
+// Publishes packages of the workspace whose version is not published yet. Members are published
+// after the members they depend on
+func (c Cmd) publishWorkspace(dir string, options publish.Options) error {
+	root, err := types.FindWorkspace(dir)
+	if err != nil {
+		return err
+	} else if root == "" {
+		return util.Error("%s is not part of a workspace, %s not found", dir, sys.Workspace)
+	}
+	members, err := types.ReadWorkspace(root)
+	if err != nil {
+		return err
+	}
+	unpublished, err := unpublishedMembers(members, client.VersionExists)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range unpublished {
+		cfg := member.Config
+		log.Infoln(log.Magenta, "Publishing %s@%s", cfg.GetName(), cfg.GetVersion())
+		options.TrialBuild = lint.TrialBuild(c.Context, member.Path)
+		if err := publish.Do(member.Path, registry.Url(), cfg, options); err != nil {
+			return util.Error("%s failed to publish: %s", cfg.GetName(), err.Error())
+		}
+	}
+
+	if len(unpublished) <= 0 {
+		log.Infoln(log.Green, "All workspace packages are already published")
+	} else {
+		log.Infoln(log.Green, "Published %d workspace packages", len(unpublished))
+	}
+	return nil
+}
+
+// Packages of the workspace whose version is not published yet in the order they are published
+func unpublishedMembers(members []*types.WorkspaceMember,
+	versionExists func(name, version string) (bool, error)) ([]*types.WorkspaceMember, error) {
+	members, err := types.OrderWorkspace(members)
+	if err != nil {
+		return nil, err
+	}
+
+	var unpublished []*types.WorkspaceMember
+	for _, member := range members {
+		cfg := member.Config
+		if cfg.GetType() != constants.Pkg {
+			continue
+		}
+		exists, err := versionExists(cfg.GetName(), cfg.GetVersion())
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			log.Verbln("%s@%s is already published", cfg.GetName(), cfg.GetVersion())
+			continue
+		}
+		unpublished = append(unpublished, member)
+	}
+	return unpublished, nil
 }
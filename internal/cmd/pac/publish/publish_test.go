@@ -0,0 +1,53 @@
+package publish
+
+import (
+	"testing"
+	"wio/internal/types"
+	"wio/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func workspaceMember(projectType, name, version string, dependencies ...string) *types.WorkspaceMember {
+	config := &types.ConfigImpl{
+		Type:         projectType,
+		Info:         &types.InfoImpl{Name: name, Version: version},
+		Dependencies: map[string]*types.DependencyImpl{},
+	}
+	for _, dependency := range dependencies {
+		config.Dependencies[dependency] = &types.DependencyImpl{Version: "^1.0.0"}
+	}
+	return &types.WorkspaceMember{Path: name, Config: config}
+}
+
+func TestUnpublishedMembers(t *testing.T) {
+	members := []*types.WorkspaceMember{
+		workspaceMember("app", "app", "0.0.1", "net"),
+		workspaceMember("pkg", "net", "1.1.0", "log"),
+		workspaceMember("pkg", "log", "1.0.0"),
+		workspaceMember("pkg", "fmt", "1.2.0"),
+	}
+	published := map[string]bool{"log@1.0.0": true}
+	var checked []string
+	versionExists := func(name, version string) (bool, error) {
+		checked = append(checked, name)
+		return published[name+"@"+version], nil
+	}
+
+	// apps and published versions are skipped, dependencies are published first
+	unpublished, err := unpublishedMembers(members, versionExists)
+	assert.NoError(t, err)
+	assert.Len(t, unpublished, 2)
+	assert.Equal(t, "net", unpublished[0].Config.GetName())
+	assert.Equal(t, "fmt", unpublished[1].Config.GetName())
+	assert.Equal(t, []string{"log", "net", "fmt"}, checked)
+
+	_, err = unpublishedMembers(members, func(name, version string) (bool, error) {
+		return false, util.Error("registry is down")
+	})
+	assert.EqualError(t, err, "registry is down")
+
+	members[2] = workspaceMember("pkg", "log", "1.0.0", "net")
+	_, err = unpublishedMembers(members, versionExists)
+	assert.EqualError(t, err, "workspace members depend on each other: app -> net -> log -> net")
+}
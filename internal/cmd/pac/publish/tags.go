@@ -0,0 +1,107 @@
+package publish
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/npm/login"
+	"wio/pkg/npm/publish"
+	"wio/pkg/npm/registry"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	ADD    = 0
+	REMOVE = 1
+	LIST   = 2
+)
+
+type DistTag struct {
+	Context *cli.Context
+	Command byte
+}
+
+func (c DistTag) GetContext() *cli.Context {
+	return c.Context
+}
+
+// Adds, removes or lists dist-tags of a package
+func (c DistTag) Execute() error {
+	login.OneTimePassword = c.Context.String("otp")
+	args := c.Context.Args()
+	switch c.Command {
+	case ADD:
+		if len(args) != 2 {
+			return util.Error("expected <package>@<version> <tag>")
+		}
+		name, version := splitSpec(args[0])
+		if semver.Parse(version) == nil {
+			return util.Error("%s is not a version of %s", version, name)
+		}
+		if err := publish.AddDistTag(registry.Url(), name, version, args[1]); err != nil {
+			return err
+		}
+		log.Infoln(log.Green, "+%s: %s@%s", args[1], name, version)
+
+	case REMOVE:
+		if len(args) != 2 {
+			return util.Error("expected <package> <tag>")
+		}
+		if args[1] == "latest" {
+			return util.Error("latest cannot be removed, point it to another version instead")
+		}
+		if err := publish.RemoveDistTag(registry.Url(), args[0], args[1]); err != nil {
+			return err
+		}
+		log.Infoln(log.Green, "-%s: %s", args[1], args[0])
+
+	case LIST:
+		name, err := packageName(args)
+		if err != nil {
+			return err
+		}
+		tags, err := publish.DistTags(registry.Url(), name)
+		if err != nil {
+			return err
+		}
+		var names []string
+		for tag := range tags {
+			names = append(names, tag)
+		}
+		sort.Strings(names)
+		for _, tag := range names {
+			log.Info(log.Cyan, "%s: ", tag)
+			log.Infoln("%s", tags[tag])
+		}
+	}
+	return nil
+}
+
+// Splits name@version, names of scoped packages start with @
+func splitSpec(spec string) (string, string) {
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		return spec[:i], spec[i+1:]
+	}
+	return spec, ""
+}
+
+// Package named in the arguments or the package in the current directory
+func packageName(args cli.Args) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	config, err := types.ReadWioConfig(dir, true)
+	if err != nil {
+		return "", util.Error("package name is missing and %s", err.Error())
+	}
+	return config.GetName(), nil
+}
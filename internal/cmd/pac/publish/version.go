@@ -0,0 +1,83 @@
+package publish
+
+import (
+	"strings"
+	"wio/internal/cmd"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/npm/publish"
+	"wio/pkg/util"
+
+	"github.com/urfave/cli"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+type Version struct {
+	Context *cli.Context
+}
+
+func (c Version) GetContext() *cli.Context {
+	return c.Context
+}
+
+// Bumps the version in wio.yml. When the project is in a git repository, wio.yml is committed and
+// the commit is tagged with the version unless --no-git is given
+func (c Version) Execute() error {
+	if c.Context.NArg() != 1 {
+		return util.Error("expected major, minor, patch, prerelease or a version")
+	}
+	dir, err := cmd.GetDirectory(c)
+	if err != nil {
+		return err
+	}
+	cfg, err := types.ReadWioConfig(dir, true)
+	if err != nil {
+		return err
+	}
+	version, err := publish.BumpVersion(cfg.GetVersion(), c.Context.Args()[0], c.Context.String("preid"))
+	if err != nil {
+		return err
+	}
+	repo, err := publish.OpenRepository(dir)
+	if err != nil {
+		return err
+	}
+	if c.Context.Bool("no-git") {
+		repo = nil
+	}
+
+	// everything the commit needs is checked first so that a failed commit does not leave
+	// wio.yml changed
+	var tag string
+	var signature *object.Signature
+	if repo != nil {
+		if tag, err = publish.VersionTag(repo, dir, cfg.GetName(), version); err != nil {
+			return err
+		}
+		if signature, err = publish.CheckRepository(repo, tag); err != nil {
+			return err
+		}
+	}
+
+	editor, err := types.EditWioConfig(dir)
+	if err != nil {
+		return err
+	}
+	if err := editor.Set(version, "project", "version"); err != nil {
+		return err
+	}
+	if err := editor.Save(); err != nil {
+		return err
+	}
+
+	if repo != nil {
+		message := strings.Replace(c.Context.String("message"), "%s", version, -1)
+		if err := publish.CommitVersion(repo, dir, message, tag, signature); err != nil {
+			return util.Error("version changed to %s but could not be committed: %s", version, err.Error())
+		}
+		log.Infoln(log.Green, "%s@%s committed and tagged %s", cfg.GetName(), version, tag)
+	} else {
+		log.Infoln(log.Green, "%s@%s", cfg.GetName(), version)
+	}
+	return nil
+}
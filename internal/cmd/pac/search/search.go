@@ -0,0 +1,62 @@
+package search
+
+import (
+	"encoding/json"
+	"strings"
+	"wio/pkg/log"
+	"wio/pkg/npm/client"
+	"wio/pkg/npm/registry"
+	"wio/pkg/util"
+
+	"github.com/urfave/cli"
+)
+
+type Cmd struct {
+	Context *cli.Context
+}
+
+func (c Cmd) GetContext() *cli.Context {
+	return c.Context
+}
+
+// Searches the registry for packages and prints their latest version, description and keywords
+func (c Cmd) Execute() error {
+	terms := []string(c.Context.Args())
+	if len(terms) <= 0 {
+		return util.Error("missing search terms")
+	}
+	size := c.Context.Int("limit")
+	if size <= 0 {
+		return util.Error("limit must be greater than 0")
+	}
+
+	packages, err := client.Search(terms, size)
+	if err != nil {
+		return util.Error("unable to search %s: %s", registry.Url(), err.Error())
+	}
+
+	if c.Context.Bool("json") {
+		data, err := json.MarshalIndent(packages, "", "  ")
+		if err != nil {
+			return err
+		}
+		log.Info("%s\n", data)
+		return nil
+	}
+
+	if len(packages) <= 0 {
+		log.Infoln("No packages found matching %s", strings.Join(terms, " "))
+		return nil
+	}
+	for _, pkg := range packages {
+		log.Info(log.Green, "%s", pkg.Name)
+		log.Infoln(log.Cyan, "@%s", pkg.Version)
+		if pkg.Description != "" {
+			log.Infoln("    %s", pkg.Description)
+		}
+		if len(pkg.Keywords) > 0 {
+			log.Infoln(log.Cyan, "    keywords: %s", strings.Join(pkg.Keywords, ", "))
+		}
+	}
+	return nil
+}
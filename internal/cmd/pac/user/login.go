@@ -2,12 +2,14 @@ package user
 
 import (
 	"bufio"
+	"io/ioutil"
 	"os"
 	"strings"
 	"syscall"
 	"wio/pkg/log"
 	"wio/pkg/npm/login"
 	"wio/pkg/npm/registry"
+	"wio/pkg/util"
 
 	"golang.org/x/crypto/ssh/terminal"
 )
@@ -40,18 +42,64 @@ func (c Login) getArgs() (*loginArgs, error) {
 	}, nil
 }
 
-func (c Login) Execute() error {
-	args, err := c.getArgs()
-	if err != nil {
-		return err
+// Reads a token from stdin, so that it does not end up in the shell history or the process list.
+// On a terminal the token is asked for without echoing it
+func readToken(input *os.File) (string, error) {
+	var data []byte
+	var err error
+	if terminal.IsTerminal(int(input.Fd())) {
+		log.Info(log.Cyan, "Token: ")
+		data, err = terminal.ReadPassword(int(input.Fd()))
+		log.Infoln()
+	} else {
+		data, err = ioutil.ReadAll(input)
 	}
-	log.Info(log.Cyan, "Sending login info ... ")
-	tokens, err := login.GetToken(args.name, args.pass, args.email, registry.WioPackageRegistry)
 	if err != nil {
-		log.WriteFailure()
-		return err
+		return "", err
 	}
-	log.WriteSuccess()
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", util.Error("no token was given on stdin")
+	}
+	return token, nil
+}
+
+// Logs in with a username and password or saves a token created on the registry with --token
+func (c Login) Execute() error {
+	login.OneTimePassword = c.Context.String("otp")
+	registryUrl := registry.Url()
+
+	var tokens *login.Tokens
+	if c.Context.Bool("token") {
+		token, err := readToken(os.Stdin)
+		if err != nil {
+			return err
+		}
+		log.Info(log.Cyan, "Checking token ... ")
+		name, err := login.Whoami(registryUrl, token)
+		if err != nil {
+			log.WriteFailure()
+			return err
+		}
+		log.WriteSuccess()
+		if tokens, err = login.ReadTokens(); err != nil {
+			return err
+		}
+		tokens.Values[registryUrl] = token
+		log.Verbln("Token belongs to %s", name)
+	} else {
+		args, err := c.getArgs()
+		if err != nil {
+			return err
+		}
+		log.Info(log.Cyan, "Sending login info ... ")
+		if tokens, err = login.GetToken(args.name, args.pass, args.email, registryUrl); err != nil {
+			log.WriteFailure()
+			return err
+		}
+		log.WriteSuccess()
+	}
+
 	log.Info(log.Cyan, "Saving login token ... ")
 	if err := tokens.Save(); err != nil {
 		log.WriteFailure()
@@ -62,3 +110,18 @@ func (c Login) Execute() error {
 
 	return nil
 }
+
+// Prints the name of the user the token for the registry belongs to
+func (c Whoami) Execute() error {
+	registryUrl := registry.Url()
+	token, err := login.LoadToken(registryUrl)
+	if err != nil {
+		return err
+	}
+	name, err := login.Whoami(registryUrl, token)
+	if err != nil {
+		return err
+	}
+	log.Infoln("%s", name)
+	return nil
+}
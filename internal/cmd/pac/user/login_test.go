@@ -0,0 +1,27 @@
+package user
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadToken(t *testing.T) {
+	file, err := ioutil.TempFile("", "wio-token")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	_, err = readToken(file)
+	assert.EqualError(t, err, "no token was given on stdin")
+
+	_, err = file.WriteString("secret\n")
+	assert.NoError(t, err)
+	_, err = file.Seek(0, 0)
+	assert.NoError(t, err)
+	token, err := readToken(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", token)
+}
@@ -1,27 +1,35 @@
 package user
 
 import (
-	"os"
-	"wio/internal/cmd"
+	"wio/internal/env"
 	"wio/pkg/log"
+	"wio/pkg/npm/login"
+	"wio/pkg/npm/registry"
 	"wio/pkg/util"
-	"wio/pkg/util/sys"
 )
 
+// Removes the saved token for the registry
 func (c Logout) Execute() error {
-	dir, err := cmd.GetDirectory(c)
+	registryUrl := registry.Url()
+	tokens, err := login.ReadTokens()
 	if err != nil {
-		return nil
+		return err
 	}
-	path := sys.Path(dir, sys.WioFolder, "token.json")
-	if !sys.Exists(path) {
+	if _, exists := tokens.Values[registryUrl]; !exists {
+		if env.GetToken() != "" {
+			return util.Error("not logged in, the token comes from WIO_TOKEN")
+		}
 		return util.Error("not logged in")
 	}
 	log.Info(log.Cyan, "Logging out... ")
-	if err := os.RemoveAll(path); err != nil {
+	delete(tokens.Values, registryUrl)
+	if err := tokens.Save(); err != nil {
 		log.WriteFailure()
 		return err
 	}
 	log.WriteSuccess()
+	if env.GetToken() != "" {
+		log.Warnln("WIO_TOKEN is still set and used for the registry")
+	}
 	return nil
 }
@@ -10,6 +10,10 @@ type Logout struct {
 	Context *cli.Context
 }
 
+type Whoami struct {
+	Context *cli.Context
+}
+
 func (cmd Login) GetContext() *cli.Context {
 	return cmd.Context
 }
@@ -17,3 +21,7 @@ func (cmd Login) GetContext() *cli.Context {
 func (cmd Logout) GetContext() *cli.Context {
 	return cmd.Context
 }
+
+func (cmd Whoami) GetContext() *cli.Context {
+	return cmd.Context
+}
@@ -6,7 +6,6 @@ import (
 	"strings"
 	"wio/internal/constants"
 	"wio/internal/types"
-	"wio/internal/utils"
 	"wio/pkg/downloader"
 	"wio/pkg/util"
 	"wio/pkg/util/sys"
@@ -51,7 +50,7 @@ func GetStandard(standard string) (string, string, error) {
 			cppStandard = val
 		} else if val, exists := cStandards[std]; exists {
 			cStandard = val
-		} else if val != "" {
+		} else if std != "" {
 			return "", "", util.Error("invalid ISO C/C++ standard [%s]", std)
 		}
 	}
@@ -84,14 +83,23 @@ var templateFiles = map[string]string{
 func GenerateCmakeLists(
 	toolchainPath string,
 	target types.Target,
+	profile types.Profile,
 	projectName string,
 	projectPath string,
+	buildPath string,
 	cppStandard string,
 	cStandard string) error {
 
 	flags := target.GetFlags().GetTarget()
 	definitions := target.GetDefinitions().GetTarget()
-	buildPath := sys.Path(utils.BuildPath(projectPath), target.GetName())
+	linkerFlags := target.GetLinkerFlags()
+	buildType := ""
+	if profile != nil {
+		flags = append(append([]string{}, flags...), profile.GetFlags()...)
+		definitions = append(append([]string{}, definitions...), profile.GetDefinitions()...)
+		linkerFlags = append(append([]string{}, linkerFlags...), profile.GetLinkerFlags()...)
+		buildType = profile.GetBuildType()
+	}
 
 	moduleData := &downloader.ModuleData{}
 	packageInfoPath := sys.Path(toolchainPath, "package.json")
@@ -110,16 +118,17 @@ func GenerateCmakeLists(
 		"PLATFORM":                   strings.ToUpper(target.GetPlatform()),
 		"FRAMEWORK":                  strings.ToUpper(target.GetFramework()),
 		"TARGET_NAME":                target.GetName(),
+		"BUILD_TYPE":                 buildType,
 		"ENTRY":                      target.GetSource(),
 		"TARGET_COMPILE_FLAGS":       strings.Join(flags, " "),
 		"TARGET_COMPILE_DEFINITIONS": strings.Join(definitions, " "),
 		"TARGET_LINK_LIBRARIES": func() string {
-			if len(target.GetLinkerFlags()) > 0 {
+			if len(linkerFlags) > 0 {
 				return "\n" + template.Replace(LinkString, map[string]string{
 					"LINK_FROM":       "${TARGET_NAME}",
 					"LINK_VISIBILITY": "PRIVATE",
 					"LINK_TO":         "# no dep",
-					"LINKER_FLAGS":    strings.Join(target.GetLinkerFlags(), " "),
+					"LINKER_FLAGS":    strings.Join(linkerFlags, " "),
 				})
 			} else {
 				return ""
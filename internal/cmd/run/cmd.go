@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
-	"strings"
+	"syscall"
+	"wio/internal/cmd"
+	"wio/internal/types"
 	"wio/pkg/log"
 	"wio/pkg/util"
 	"wio/pkg/util/sys"
@@ -25,13 +28,83 @@ func uploadTarget(dir string) error {
 	return Execute(dir, util.GetMake(), "upload")
 }
 
-func runTarget(dir, file, args string) error {
-	var argv []string
-	if args != "" {
-		argv = strings.Split(args, " ")
+// settings used when executing a native target
+type runSettings struct {
+	args  []string
+	env   []string
+	dir   string
+	stdin string
+}
+
+// Reads run settings of the target from wio.yml and overrides arguments if they are provided
+func getRunSettings(info *runInfo, target types.Target) (*runSettings, error) {
+	run := target.GetRun()
+	settings := &runSettings{
+		args: run.GetArgs(),
+		dir:  projectRelative(info.directory, run.GetWorkingDir()),
+	}
+
+	if info.context.IsSet("args") {
+		args, err := util.SplitArgs(info.context.String("args"))
+		if err != nil {
+			return nil, err
+		}
+		settings.args = args
+	}
+
+	for name, value := range run.GetEnv() {
+		settings.env = append(settings.env, fmt.Sprintf("%s=%s", name, value))
+	}
+
+	if !util.IsEmptyString(run.GetStdin()) {
+		settings.stdin = projectRelative(info.directory, run.GetStdin())
+		if !sys.Exists(settings.stdin) {
+			return nil, util.Error("stdin file %s does not exist", settings.stdin)
+		}
 	}
 
-	return Execute(dir, file, argv...)
+	return settings, nil
+}
+
+// paths in run settings are relative to the project directory
+func projectRelative(projectDir, path string) string {
+	if util.IsEmptyString(path) {
+		return projectDir
+	} else if filepath.IsAbs(path) {
+		return path
+	}
+	return sys.Path(projectDir, path)
+}
+
+func runTarget(file string, settings *runSettings) error {
+	command := exec.Command(file, settings.args...)
+	command.Dir = settings.dir
+	command.Env = append(os.Environ(), settings.env...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	if settings.stdin != "" {
+		stdin, err := os.Open(settings.stdin)
+		if err != nil {
+			return err
+		}
+		defer stdin.Close()
+		command.Stdin = stdin
+	}
+
+	log.Verbln(log.Magenta, "Executing %s in %s", file, settings.dir)
+	return exitStatus(command.Run())
+}
+
+// wraps exit status of the executable so that wio exits with the same code
+func exitStatus(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return cmd.NewExitError(status.ExitStatus(), err)
+		}
+	}
+	return err
 }
 
 func cleanTarget(dir string) error {
@@ -213,7 +213,7 @@ func CreateBuildTargets(projectDir string, target types.Target) (*TargetSet, *Ta
 	libraryTargetSet := NewTargetSet()
 
 	i := resolve.NewInfo(projectDir)
-	config, err := types.ReadWioConfig(projectDir, true)
+	config, err := types.ReadTargetConfig(projectDir, target)
 	if err != nil {
 		return nil, nil, err
 	}
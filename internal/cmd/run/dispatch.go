@@ -19,6 +19,7 @@ func dispatchRunTarget(info *runInfo, target types.Target) error {
 			Directory:   info.directory,
 			ProjectType: info.projectType,
 			Port:        port,
+			Profile:     info.profile,
 		}
 
 		if err := generate.HardwareFile(infoGen, target); err != nil {
@@ -28,8 +29,22 @@ func dispatchRunTarget(info *runInfo, target types.Target) error {
 		return nil
 	}
 
+	simulate := info.context.Bool("simulate")
+	if simulate && platform != constants.Avr {
+		return util.Error("simulation is not supported for platform [%s]", platform)
+	}
+
 	switch platform {
 	case constants.Avr:
+		if simulate {
+			firmware := sys.Path(binDir, target.GetName()+platformExtension(platform))
+			settings, err := getSimulateSettings(info, target, firmware)
+			if err != nil {
+				return err
+			}
+			return simulateTarget(info.directory, settings)
+		}
+
 		if info.port == "none" {
 			return util.Error("no serial port provided")
 		}
@@ -48,8 +63,11 @@ func dispatchRunTarget(info *runInfo, target types.Target) error {
 			return err
 		}
 
-		args := info.context.String("args")
-		return runTarget(info.directory, sys.Path(binDir, target.GetName()), args)
+		settings, err := getRunSettings(info, target)
+		if err != nil {
+			return err
+		}
+		return runTarget(sys.Path(binDir, target.GetName()), settings)
 	default:
 		return util.Error("platform [%s] is not supported", platform)
 	}
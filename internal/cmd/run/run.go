@@ -7,8 +7,11 @@
 package run
 
 import (
+	"fmt"
 	"os"
 	"runtime"
+	"sort"
+	"strings"
 	"wio/internal/cmd/generate"
 	"wio/internal/types"
 	"wio/pkg/log"
@@ -40,10 +43,12 @@ type runInfo struct {
 	headerOnly  bool
 	targets     []string
 	port        string
+	profile     types.Profile
 
 	runType Type
 	jobs    int
 
+	all    bool
 	force  bool
 	retool bool
 }
@@ -67,12 +72,33 @@ func (run Run) Execute() error {
 	if err != nil {
 		return err
 	}
-	config, err := types.ReadWioConfig(directory, true)
+	if run.Context.Bool("workspace") {
+		return run.buildWorkspace(directory)
+	}
+
+	info, err := run.newRunInfo(directory, run.Context.Args())
 	if err != nil {
 		return err
 	}
-	targets := run.Context.Args()
-	info := runInfo{
+	if err := info.execute(run.RunType); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (run Run) newRunInfo(directory string, targets []string) (*runInfo, error) {
+	config, err := types.ReadWioConfig(directory, true)
+	if err != nil {
+		return nil, err
+	}
+	if types.NeedsMigration(config) {
+		log.Warnln("wio.yml uses an older schema, run `wio update --migrate` to migrate it")
+	}
+	profile, err := getProfile(config, run.Context.String("profile"))
+	if err != nil {
+		return nil, err
+	}
+	return &runInfo{
 		context:     run.Context,
 		config:      config,
 		directory:   directory,
@@ -80,13 +106,21 @@ func (run Run) Execute() error {
 		headerOnly:  config.GetInfo().GetOptions().GetIsHeaderOnly(),
 		targets:     targets,
 		port:        run.Context.String("port"),
+		profile:     profile,
+		all:         run.Context.Bool("all"),
 		force:       run.Context.Bool("force"),
 		retool:      run.Context.Bool("retool"),
-	}
-	if err := info.execute(run.RunType); err != nil {
+	}, nil
+}
+
+// Builds every target of the project in directory
+func BuildAll(context *cli.Context, directory string) error {
+	info, err := Run{Context: context}.newRunInfo(directory, nil)
+	if err != nil {
 		return err
 	}
-	return nil
+	info.all = true
+	return info.execute(TypeBuild)
 }
 
 func (info *runInfo) execute(runType Type) error {
@@ -144,13 +178,95 @@ func (info *runInfo) build(targets []types.Target) error {
 
 	log.Infoln(log.Magenta, "Running build with JOBS=%d", runtime.NumCPU()+2)
 	errs := asyncBuildTargets(targetDirs)
-	return awaitErrors(errs)
+	if len(targets) <= 1 {
+		return awaitErrors(errs)
+	}
+
+	results := make([]error, 0, len(errs))
+	failed := 0
+	for _, errChan := range errs {
+		err := <-errChan
+		if err != nil {
+			failed++
+		}
+		results = append(results, err)
+	}
+
+	info.printBuildSummary(targets, results)
+	if failed > 0 {
+		return util.Error("%d of %d targets failed to build", failed, len(targets))
+	}
+	return nil
+}
+
+// Prints a table with build status and binary size of every target
+func (info *runInfo) printBuildSummary(targets []types.Target, results []error) {
+	header := []string{"TARGET", "BOARD", "FRAMEWORK", "STATUS", "SIZE"}
+	rows := info.buildSummaryRows(targets, results)
+
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, column := range row {
+			if len(column) > widths[i] {
+				widths[i] = len(column)
+			}
+		}
+	}
+
+	pad := func(column string, i int) string {
+		return fmt.Sprintf("%-*s  ", widths[i], column)
+	}
+
+	log.Infoln()
+	for i, column := range header {
+		log.Info(log.Cyan, "%s", pad(column, i))
+	}
+	log.Infoln()
+	for _, row := range rows {
+		for i, column := range row {
+			if i == 3 && column == "pass" {
+				log.Info(log.Green, "%s", pad(column, i))
+			} else if i == 3 {
+				log.Info(log.Red, "%s", pad(column, i))
+			} else {
+				log.Info("%s", pad(column, i))
+			}
+		}
+		log.Infoln()
+	}
+}
+
+func (info *runInfo) buildSummaryRows(targets []types.Target, results []error) [][]string {
+	rows := make([][]string, 0, len(targets))
+	for i, target := range targets {
+		status := "pass"
+		size := "-"
+		if results[i] != nil {
+			status = "fail"
+		} else if bytes, err := binarySize(info, target); err == nil {
+			size = fmt.Sprintf("%d B", bytes)
+		}
+		rows = append(rows, []string{target.GetName(), target.GetBoard(), target.GetFramework(), status, size})
+	}
+	return rows
 }
 
 func (info *runInfo) run(targets []types.Target) error {
+	if len(targets) > 1 {
+		names := make([]string, 0, len(targets))
+		for _, target := range targets {
+			names = append(names, target.GetName())
+		}
+		return util.Error("only one target can be run, pick one of: %s", strings.Join(names, ", "))
+	}
+
 	target := targets[0]
 	log.Info(log.Cyan, "Target: ")
 	log.Infoln(log.Magenta, target.GetName())
+	if info.profile.GetName() != "" {
+		log.Info(log.Cyan, "Profile: ")
+		log.Infoln(log.Magenta, info.profile.GetName())
+	}
 	if !dispatchCanRunTarget(info, target) {
 		if err := info.build(targets[:1]); err != nil {
 			return err
@@ -162,31 +278,54 @@ func (info *runInfo) run(targets []types.Target) error {
 func getTargetArgs(info *runInfo) ([]types.Target, error) {
 	targets := make([]types.Target, 0, len(info.targets))
 	projectTargets := info.config.GetTargets()
+	for name, target := range projectTargets {
+		target.SetName(name)
+	}
 
-	if info.context.Bool("all") {
-		for name, target := range projectTargets {
-			target.SetName(name)
+	sortTargets := func(targets []types.Target) {
+		sort.Slice(targets, func(i, j int) bool {
+			return targets[i].GetName() < targets[j].GetName()
+		})
+	}
+
+	// targets with boards or frameworks are expanded into a build matrix
+	findTargets := func(name string) []types.Target {
+		if target, exists := projectTargets[name]; exists {
+			return []types.Target{target}
+		}
+		var matrix []types.Target
+		for _, target := range projectTargets {
+			if target.GetMatrix() == name {
+				matrix = append(matrix, target)
+			}
+		}
+		sortTargets(matrix)
+		return matrix
+	}
+
+	if info.all {
+		for _, target := range projectTargets {
 			targets = append(targets, target)
 		}
+		sortTargets(targets)
 	} else {
 		for _, name := range info.targets {
-			if _, exists := projectTargets[name]; exists {
-				projectTargets[name].SetName(name)
-				targets = append(targets, projectTargets[name])
-			} else {
+			found := findTargets(name)
+			if len(found) <= 0 {
 				return nil, util.Error("unrecognized target %s", name)
 			}
+			targets = append(targets, found...)
 		}
 		if len(info.targets) <= 0 {
 			defaultName := info.config.GetInfo().GetOptions().GetDefault()
 			if defaultName == "" {
 				return nil, util.Error("no default target specified")
 			}
-			if _, exists := projectTargets[defaultName]; !exists {
+			found := findTargets(defaultName)
+			if len(found) <= 0 {
 				return nil, util.Error("default target %s does not exist", defaultName)
 			}
-			projectTargets[defaultName].SetName(defaultName)
-			targets = append(targets, projectTargets[defaultName])
+			targets = append(targets, found...)
 		}
 	}
 	return targets, nil
@@ -200,10 +339,12 @@ func configureTargetsBuildFiles(info *runInfo, targets []types.Target) ([]string
 		Directory:   info.directory,
 		ProjectType: info.projectType,
 		Port:        info.port,
+		Profile:     info.profile,
+		Retool:      info.retool,
 	}
 
 	for _, target := range targets {
-		buildStatus, err := shouldCreateBuildFiles(info.directory, target.GetName())
+		buildStatus, err := shouldCreateBuildFiles(info.directory, targetPath(info, target))
 		if err != nil {
 			return nil, err
 		}
@@ -213,7 +354,10 @@ func configureTargetsBuildFiles(info *runInfo, targets []types.Target) ([]string
 			os.RemoveAll(wioTimeFile)
 		}
 
-		if info.retool || info.force || buildStatus {
+		// packages used from a path can change at any time and toolchains change when their
+		// version in wio.lock does
+		if info.retool || info.force || buildStatus || hasPathDependencies(info.directory, target) ||
+			generate.ToolchainChanged(infoGen, target) {
 			log.Infoln(log.Cyan, "Generating CMake build files for target %s", target.GetName())
 
 			if err := generate.CMakeListsFile(infoGen, target); err != nil {
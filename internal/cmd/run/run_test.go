@@ -0,0 +1,100 @@
+package run
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"wio/internal/types"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const matrixConfig = `type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.10.0
+    default_target: tests
+targets:
+  main:
+    src: src
+    platform: native
+  tests:
+    src: tests
+    platform: avr
+    boards: [uno, mega2560]
+    frameworks: [arduino, cosa]
+`
+
+func matrixRunInfo(t *testing.T) *runInfo {
+	dir, err := ioutil.TempDir("", "wio-run")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(sys.Path(dir, sys.Config), []byte(matrixConfig), 0644))
+
+	config, err := types.ReadWioConfig(dir, true)
+	assert.NoError(t, err)
+	return &runInfo{config: config, directory: dir, profile: (*types.ProfileImpl)(nil)}
+}
+
+func targetNames(targets []types.Target) []string {
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.GetName())
+	}
+	return names
+}
+
+func TestGetTargetArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []string
+		all     bool
+		found   []string
+	}{
+		{"default target matrix", nil, false, []string{
+			"tests__mega2560__arduino", "tests__mega2560__cosa", "tests__uno__arduino", "tests__uno__cosa",
+		}},
+		{"expanded target", []string{"tests__uno__cosa"}, false, []string{"tests__uno__cosa"}},
+		{"targets in order", []string{"main", "tests__uno__arduino"}, false, []string{"main", "tests__uno__arduino"}},
+		{"all targets", []string{"main"}, true, []string{
+			"main", "tests__mega2560__arduino", "tests__mega2560__cosa", "tests__uno__arduino", "tests__uno__cosa",
+		}},
+	}
+
+	for _, test := range tests {
+		info := matrixRunInfo(t)
+		defer os.RemoveAll(info.directory)
+		info.targets = test.targets
+		info.all = test.all
+
+		targets, err := getTargetArgs(info)
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.found, targetNames(targets), test.name)
+	}
+
+	info := matrixRunInfo(t)
+	defer os.RemoveAll(info.directory)
+	info.targets = []string{"tests__uno"}
+	_, err := getTargetArgs(info)
+	assert.EqualError(t, err, "unrecognized target tests__uno")
+}
+
+func TestBuildSummaryRows(t *testing.T) {
+	info := matrixRunInfo(t)
+	defer os.RemoveAll(info.directory)
+	info.targets = []string{"main", "tests__uno__arduino", "tests__uno__cosa"}
+	targets, err := getTargetArgs(info)
+	assert.NoError(t, err)
+
+	binary := sys.Path(binaryPath(info, targets[0]), "main"+nativeExtension())
+	assert.NoError(t, os.MkdirAll(binaryPath(info, targets[0]), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(binary, []byte("binary"), 0644))
+
+	assert.Equal(t, [][]string{
+		{"main", "", "", "pass", "6 B"},
+		{"tests__uno__arduino", "uno", "arduino", "fail", "-"},
+		{"tests__uno__cosa", "uno", "cosa", "pass", "-"},
+	}, info.buildSummaryRows(targets, []error{nil, util.Error("failed"), nil}))
+}
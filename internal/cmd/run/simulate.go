@@ -0,0 +1,209 @@
+package run
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"wio/internal/cmd"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/util"
+)
+
+// exit code used when simulation does not finish in time, same as coreutils timeout
+const simulationTimeoutCode = 124
+
+const defaultSimulatorCommand = "simavr -m {{MCU}} -f {{FREQUENCY}} {{FIRMWARE}}"
+
+type avrChip struct {
+	mcu       string
+	frequency uint64
+}
+
+// mcu and clock of boards supported by the arduino toolchain
+var avrBoards = map[string]avrChip{
+	"uno":          {"atmega328p", 16000000},
+	"nano":         {"atmega328p", 16000000},
+	"mini":         {"atmega328p", 16000000},
+	"ethernet":     {"atmega328p", 16000000},
+	"bt":           {"atmega328p", 16000000},
+	"fio":          {"atmega328p", 8000000},
+	"lilypad":      {"atmega328p", 8000000},
+	"pro":          {"atmega328p", 16000000},
+	"pro5v328":     {"atmega328p", 16000000},
+	"pro328":       {"atmega328p", 8000000},
+	"diecimila":    {"atmega168", 16000000},
+	"atmegang":     {"atmega168", 16000000},
+	"mega":         {"atmega2560", 16000000},
+	"mega2560":     {"atmega2560", 16000000},
+	"megaADK":      {"atmega2560", 16000000},
+	"leonardo":     {"atmega32u4", 16000000},
+	"micro":        {"atmega32u4", 16000000},
+	"esplora":      {"atmega32u4", 16000000},
+	"yun":          {"atmega32u4", 16000000},
+	"robotControl": {"atmega32u4", 16000000},
+	"robotMotor":   {"atmega32u4", 16000000},
+}
+
+type simulateSettings struct {
+	argv    []string
+	timeout time.Duration
+	exitOn  *regexp.Regexp
+	failOn  *regexp.Regexp
+}
+
+// Reads simulator settings of the target from wio.yml and command line flags
+func getSimulateSettings(info *runInfo, target types.Target, firmware string) (*simulateSettings, error) {
+	simulator := target.GetRun().GetSimulator()
+	settings := &simulateSettings{}
+
+	chip := avrBoards[target.GetBoard()]
+	if simulator.GetMcu() != "" {
+		chip.mcu = simulator.GetMcu()
+	}
+	if simulator.GetFrequency() != 0 {
+		chip.frequency = simulator.GetFrequency()
+	}
+	if chip.mcu == "" || chip.frequency == 0 {
+		return nil, util.Error("unknown mcu for board %s, specify run.simulator.mcu and run.simulator.frequency",
+			target.GetBoard())
+	}
+
+	command := defaultSimulatorCommand
+	if info.context.IsSet("simulator") {
+		command = info.context.String("simulator")
+	} else if simulator.GetCommand() != "" {
+		command = simulator.GetCommand()
+	}
+	argv, err := util.SplitArgs(command)
+	if err != nil {
+		return nil, err
+	} else if len(argv) <= 0 {
+		return nil, util.Error("simulator command is empty")
+	}
+	replacer := strings.NewReplacer(
+		"{{MCU}}", chip.mcu,
+		"{{FREQUENCY}}", strconv.FormatUint(chip.frequency, 10),
+		"{{FIRMWARE}}", firmware)
+	for i, arg := range argv {
+		argv[i] = replacer.Replace(arg)
+	}
+	settings.argv = argv
+
+	if info.context.IsSet("timeout") {
+		settings.timeout = info.context.Duration("timeout")
+	} else if simulator.GetTimeout() != "" {
+		if settings.timeout, err = time.ParseDuration(simulator.GetTimeout()); err != nil {
+			return nil, util.Error("invalid simulator timeout %s", simulator.GetTimeout())
+		}
+	}
+
+	if simulator.GetExitOn() != "" {
+		if settings.exitOn, err = regexp.Compile(simulator.GetExitOn()); err != nil {
+			return nil, util.Error("invalid simulator exit_on pattern: %s", err.Error())
+		}
+	}
+	if simulator.GetFailOn() != "" {
+		if settings.failOn, err = regexp.Compile(simulator.GetFailOn()); err != nil {
+			return nil, util.Error("invalid simulator fail_on pattern: %s", err.Error())
+		}
+	}
+
+	return settings, nil
+}
+
+// Runs firmware in the simulator. The simulation ends when the simulator exits, the output matches
+// exit or fail pattern or the timeout is reached
+func simulateTarget(dir string, settings *simulateSettings) error {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	command := exec.Command(settings.argv[0], settings.argv[1:]...)
+	command.Dir = dir
+	// simavr writes uart output to stderr so both streams are forwarded to stdout
+	command.Stdout = writer
+	command.Stderr = writer
+
+	log.Verbln(log.Magenta, "Executing %s", strings.Join(settings.argv, " "))
+	err = command.Start()
+	writer.Close()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- command.Wait()
+	}()
+
+	matched := make(chan error, 1)
+	finished := make(chan struct{})
+	go scanSimulatorOutput(reader, settings, matched, finished)
+
+	var timeout <-chan time.Time
+	if settings.timeout > 0 {
+		timeout = time.After(settings.timeout)
+	}
+
+	select {
+	case err := <-done:
+		<-finished
+		select {
+		case result := <-matched:
+			return result
+		default:
+			return exitStatus(err)
+		}
+	case result := <-matched:
+		stopSimulator(command, done, reader, finished)
+		return result
+	case <-timeout:
+		stopSimulator(command, done, reader, finished)
+		return cmd.NewExitError(simulationTimeoutCode,
+			util.Error("simulation timed out after %s", settings.timeout))
+	}
+}
+
+// Kills the simulator and stops reading its output, which may still be held open by child processes
+func stopSimulator(command *exec.Cmd, done chan error, reader *os.File, finished chan struct{}) {
+	command.Process.Kill()
+	<-done
+	reader.Close()
+	<-finished
+}
+
+// Forwards simulator output to stdout and reports the first line matching exit or fail pattern
+func scanSimulatorOutput(reader io.Reader, settings *simulateSettings, matched chan error, finished chan struct{}) {
+	defer close(finished)
+	reported := false
+	report := func(err error) {
+		if !reported {
+			reported = true
+			matched <- err
+		}
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Fprintln(os.Stdout, line)
+
+		if settings.failOn != nil && settings.failOn.MatchString(line) {
+			report(cmd.NewExitError(1, util.Error("simulation failed, output matched %s", settings.failOn)))
+		} else if settings.exitOn != nil && settings.exitOn.MatchString(line) {
+			report(nil)
+		}
+	}
+	// keep draining so the simulator never blocks on a full pipe
+	io.Copy(ioutil.Discard, reader)
+}
@@ -0,0 +1,56 @@
+package run
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"wio/internal/cmd"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanSimulatorOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		exitOn  string
+		failOn  string
+		matched bool
+		code    int
+	}{
+		{"no patterns", "PASS\nFAIL\n", "", "", false, 0},
+		{"exit on", "starting\nPASS 3 tests\nafter\n", "^PASS", "", true, 0},
+		{"fail on", "starting\nFAIL test_uart\n", "^PASS", "^FAIL", true, 1},
+		{"first match wins", "PASS\nFAIL\n", "^PASS", "^FAIL", true, 0},
+		{"fail before exit", "FAIL\nPASS\n", "^PASS", "^FAIL", true, 1},
+		{"fail checked first", "PASS FAIL\n", "PASS", "FAIL", true, 1},
+		{"no match", "starting\nrunning\n", "^PASS", "^FAIL", false, 0},
+	}
+	for _, test := range tests {
+		settings := &simulateSettings{}
+		if test.exitOn != "" {
+			settings.exitOn = regexp.MustCompile(test.exitOn)
+		}
+		if test.failOn != "" {
+			settings.failOn = regexp.MustCompile(test.failOn)
+		}
+
+		// only the first match is reported so a buffer of one never blocks
+		matched := make(chan error, 1)
+		finished := make(chan struct{})
+		scanSimulatorOutput(strings.NewReader(test.output), settings, matched, finished)
+		<-finished
+
+		select {
+		case err := <-matched:
+			assert.True(t, test.matched, test.name)
+			if test.code == 0 {
+				assert.NoError(t, err, test.name)
+			} else if assert.Error(t, err, test.name) {
+				assert.Equal(t, test.code, err.(cmd.ExitError).ExitCode(), test.name)
+			}
+		default:
+			assert.False(t, test.matched, test.name)
+		}
+	}
+}
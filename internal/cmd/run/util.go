@@ -3,9 +3,13 @@ package run
 import (
 	"os"
 	"strconv"
+	"strings"
+	"wio/internal/config/defaults"
 	"wio/internal/constants"
 	"wio/internal/types"
 	"wio/internal/utils"
+	"wio/pkg/npm/resolve"
+	"wio/pkg/util"
 	"wio/pkg/util/sys"
 )
 
@@ -14,7 +18,35 @@ func buildPath(info *runInfo) string {
 }
 
 func targetPath(info *runInfo, target types.Target) string {
-	return sys.Path(buildPath(info), target.GetName())
+	return utils.TargetPath(info.directory, target.GetName(), info.profile.GetName())
+}
+
+// Resolves profile provided by the user, profiles in wio.yml extend built-in profiles
+func getProfile(config types.Config, name string) (types.Profile, error) {
+	if util.IsEmptyString(name) {
+		return (*types.ProfileImpl)(nil), nil
+	}
+
+	builtin, builtinExists := defaults.Profiles[name]
+	custom, customExists := config.GetProfiles()[name]
+	if !builtinExists && !customExists {
+		return nil, util.Error("unrecognized profile %s", name)
+	}
+
+	profile := &types.ProfileImpl{}
+	if builtinExists {
+		*profile = *builtin
+	}
+	if customExists {
+		if custom.GetBuildType() != "" {
+			profile.BuildType = custom.GetBuildType()
+		}
+		profile.Flags = append(append([]string{}, profile.Flags...), custom.GetFlags()...)
+		profile.Definitions = append(append([]string{}, profile.Definitions...), custom.GetDefinitions()...)
+		profile.LinkerFlags = append(append([]string{}, profile.LinkerFlags...), custom.GetLinkerFlags()...)
+	}
+	profile.SetName(name)
+	return profile, nil
 }
 
 func binaryPath(info *runInfo, target types.Target) string {
@@ -41,9 +73,47 @@ func platformExtension(platform string) string {
 	}
 }
 
-func shouldCreateBuildFiles(projectDir string, targetName string) (bool, error) {
-	wioTimeFile := sys.Path(utils.BuildPath(projectDir), targetName, "wio.time")
-	wioCMakeListsFile := sys.Path(utils.BuildPath(projectDir), targetName, "CMakeLists.txt")
+// Size of the built binary. For AVR this is the size of the firmware written to flash
+func binarySize(info *runInfo, target types.Target) (int64, error) {
+	binDir := binaryPath(info, target)
+
+	if target.GetPlatform() == constants.Avr {
+		return hexDataSize(sys.Path(binDir, target.GetName()+".hex"))
+	}
+
+	stat, err := os.Stat(sys.Path(binDir, target.GetName()+platformExtension(target.GetPlatform())))
+	if err != nil {
+		return 0, err
+	}
+	return stat.Size(), nil
+}
+
+// Counts data bytes in an Intel HEX file
+func hexDataSize(path string) (int64, error) {
+	data, err := sys.NormalIO.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		// record format is :LLAAAATT[DD...]CC and type 00 is a data record
+		if len(line) < 11 || line[0] != ':' || line[7:9] != "00" {
+			continue
+		}
+		length, err := strconv.ParseInt(line[1:3], 16, 64)
+		if err != nil {
+			return 0, err
+		}
+		size += length
+	}
+	return size, nil
+}
+
+func shouldCreateBuildFiles(projectDir string, targetDir string) (bool, error) {
+	wioTimeFile := sys.Path(targetDir, "wio.time")
+	wioCMakeListsFile := sys.Path(targetDir, "CMakeLists.txt")
 
 	// check if CMakeLists.txt file exists
 	if !sys.Exists(wioCMakeListsFile) {
@@ -94,3 +164,19 @@ func shouldCreateBuildFiles(projectDir string, targetName string) (bool, error)
 
 	return true, nil
 }
+
+// Checks whether a package in the dependency tree of a target is used from a path, including
+// dependencies of vendored and installed packages. When the tree cannot be resolved, build files
+// are generated again so that the error is shown
+func hasPathDependencies(dir string, target types.Target) bool {
+	config, err := types.ReadTargetConfig(dir, target)
+	if err != nil {
+		return true
+	}
+	info := resolve.NewInfo(dir)
+	info.SetQuiet()
+	if err := info.ResolveRemote(config, false); err != nil {
+		return true
+	}
+	return info.GetRoot().HasLinked()
+}
@@ -0,0 +1,51 @@
+package run
+
+import (
+	"path/filepath"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+)
+
+// Builds every member of the workspace after the members it depends on
+func (run Run) buildWorkspace(directory string) error {
+	if len(run.Context.Args()) > 0 {
+		return util.Error("targets cannot be provided when building a workspace")
+	}
+
+	root, err := types.FindWorkspace(directory)
+	if err != nil {
+		return err
+	} else if root == "" {
+		return util.Error("%s is not part of a workspace, %s not found", directory, sys.Workspace)
+	}
+	members, err := types.ReadWorkspace(root)
+	if err != nil {
+		return err
+	}
+	if members, err = types.OrderWorkspace(members); err != nil {
+		return err
+	}
+
+	for n, member := range members {
+		relative, err := filepath.Rel(root, member.Path)
+		if err != nil {
+			relative = member.Path
+		}
+		log.Infoln(log.Magenta, "[%d/%d] Building %s (%s)", n+1, len(members), member.Config.GetName(),
+			filepath.ToSlash(relative))
+
+		info, err := run.newRunInfo(member.Path, nil)
+		if err != nil {
+			return err
+		}
+		if err := info.execute(TypeBuild); err != nil {
+			return util.Error("%s failed to build: %s", member.Config.GetName(), err.Error())
+		}
+		log.Writeln()
+	}
+
+	log.Infoln(log.Green, "Built %d workspace members", len(members))
+	return nil
+}
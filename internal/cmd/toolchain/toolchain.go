@@ -0,0 +1,203 @@
+// Part of toolchain package, which manages toolchains downloaded for building projects
+package toolchain
+
+import (
+	"fmt"
+	"os"
+	"wio/internal/constants"
+	"wio/internal/types"
+	"wio/pkg/downloader"
+	"wio/pkg/log"
+	"wio/pkg/npm/resolve"
+	"wio/pkg/util"
+
+	"github.com/urfave/cli"
+)
+
+type Toolchain struct {
+	Context *cli.Context
+	Command byte
+}
+
+// get context for the command
+func (toolchain Toolchain) GetContext() *cli.Context {
+	return toolchain.Context
+}
+
+const (
+	LIST    = 0
+	INSTALL = 1
+	REMOVE  = 2
+	PRUNE   = 3
+)
+
+// Runs the toolchain subcommand
+func (toolchain Toolchain) Execute() error {
+	switch toolchain.Command {
+	case LIST:
+		return toolchain.list()
+	case INSTALL:
+		return toolchain.install()
+	case REMOVE:
+		return toolchain.remove()
+	case PRUNE:
+		return toolchain.prune()
+	default:
+		return util.Error("invalid toolchain command")
+	}
+}
+
+// Lists installed toolchains with their version and disk usage
+func (toolchain Toolchain) list() error {
+	toolchains, err := downloader.ListToolchains()
+	if err != nil {
+		return err
+	}
+	if len(toolchains) <= 0 {
+		log.Infoln("No toolchains are installed")
+		return nil
+	}
+
+	var total int64
+	for _, t := range toolchains {
+		source := "npm"
+		if t.Git {
+			source = "git"
+		}
+		version := t.Version
+		if version == "" {
+			version = "-"
+		}
+		log.Info(log.Green, "%-40s ", t.Name)
+		log.Infoln("%-12s %-10s %-4s %10s", t.Ref, version, source, util.FormatSize(t.Size))
+		total += t.Size
+	}
+	log.Infoln(log.Cyan, "%d toolchains using %s", len(toolchains), util.FormatSize(total))
+	return nil
+}
+
+// Downloads a toolchain so that builds do not have to
+func (toolchain Toolchain) install() error {
+	if toolchain.Context.NArg() != 1 {
+		return util.Error("expected a toolchain: e.g. 'arduino', 'cosa:1.0.0' or 'github.com/foo/bar:master'")
+	}
+	path, err := downloader.DownloadToolchain(toolchain.Context.Args()[0], nil, toolchain.Context.Bool("retool"))
+	if err != nil {
+		return err
+	}
+	log.Infoln(log.Green, "Toolchain installed in %s", path)
+	return nil
+}
+
+// Removes a toolchain at a ref or all of its refs when none is given
+func (toolchain Toolchain) remove() error {
+	if toolchain.Context.NArg() != 1 {
+		return util.Error("expected a toolchain: e.g. 'arduino' or 'cosa:1.0.0'")
+	}
+	name, ref, _, err := downloader.ParseToolchainLink(toolchain.Context.Args()[0])
+	if err != nil {
+		return err
+	}
+	toolchains, err := downloader.ListToolchains()
+	if err != nil {
+		return err
+	}
+
+	var matching []downloader.Toolchain
+	for _, t := range toolchains {
+		if t.Name == name && (ref == "" || t.Ref == ref) {
+			matching = append(matching, t)
+		}
+	}
+	if len(matching) <= 0 {
+		return util.Error("toolchain %s is not installed", toolchain.Context.Args()[0])
+	}
+	return removeToolchains(matching)
+}
+
+// Removes toolchains not used by targets of the given projects, the current directory by default
+func (toolchain Toolchain) prune() error {
+	dirs := []string(toolchain.Context.Args())
+	if len(dirs) <= 0 {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		dirs = []string{dir}
+	}
+
+	var links []string
+	var locks []*types.LockImpl
+	for _, dir := range dirs {
+		config, err := types.ReadWioConfig(dir, true)
+		if err != nil {
+			return err
+		}
+		lock, err := types.ReadLock(dir)
+		if err != nil {
+			return err
+		}
+		locks = append(locks, lock)
+		for _, target := range config.GetTargets() {
+			if target.GetPlatform() == constants.Native || target.GetFramework() == "" {
+				continue
+			}
+			links = append(links, lockedLink(target.GetFramework(), lock))
+		}
+	}
+
+	unused, err := downloader.UnusedToolchains(links, locks)
+	if err != nil {
+		return err
+	}
+	if len(unused) <= 0 {
+		log.Infoln(log.Green, "All toolchains are used")
+		return nil
+	}
+
+	var total int64
+	for _, t := range unused {
+		log.Infoln("%s %s", t.Folder(), util.FormatSize(t.Size))
+		total += t.Size
+	}
+	if !toolchain.Context.Bool("yes") {
+		confirmed, err := log.PromptYes(fmt.Sprintf("Remove %d unused toolchains using %s?", len(unused),
+			util.FormatSize(total)))
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+	return removeToolchains(unused)
+}
+
+// Replaces the version range of a toolchain from the registry with the version it is locked to and
+// the reference of a toolchain from git with the commit it is locked to
+func lockedLink(link string, lock *types.LockImpl) string {
+	name, ref, isGit, err := downloader.ParseToolchainLink(link)
+	if err != nil {
+		return link
+	}
+	if ref == "" && isGit {
+		ref = downloader.DefaultRef
+	} else if ref == "" {
+		ref = resolve.Latest
+	}
+	if version := lock.ToolchainVersion(name, ref); version != "" {
+		return name + ":" + version
+	}
+	return link
+}
+
+func removeToolchains(toolchains []downloader.Toolchain) error {
+	for _, t := range toolchains {
+		log.Info(log.Cyan, "Removing ")
+		log.Info(log.Green, "%s ", t.Folder())
+		log.Info(log.Cyan, "... ")
+		if err := downloader.RemoveToolchain(t); err != nil {
+			log.WriteFailure()
+			return err
+		}
+		log.WriteSuccess()
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package toolchain
+
+import (
+	"testing"
+	"wio/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockedLink(t *testing.T) {
+	lock := &types.LockImpl{}
+	lock.SetToolchainVersion("wio-framework-avr-cosa", "^1.0.0", "1.2.0")
+	lock.SetToolchainVersion("wio-framework-avr-arduino", "latest", "2.1.0")
+	lock.SetToolchainVersion("github.com/wio/toolchain", "master", "1a2b3c")
+	lock.SetToolchainVersion("github.com/wio/toolchain", "v1", "4d5e6f")
+
+	tests := []struct {
+		link   string
+		locked string
+	}{
+		{"cosa:^1.0.0", "wio-framework-avr-cosa:1.2.0"},
+		{"wio-framework-avr-cosa:^1.0.0", "wio-framework-avr-cosa:1.2.0"},
+		{"cosa:~1.1.0", "cosa:~1.1.0"},
+		{"arduino", "wio-framework-avr-arduino:2.1.0"},
+		{"arduino:1.0.0", "arduino:1.0.0"},
+		{"github.com/wio/toolchain", "github.com/wio/toolchain:1a2b3c"},
+		{"github.com/wio/toolchain:master", "github.com/wio/toolchain:1a2b3c"},
+		{"github.com/wio/toolchain:v1", "github.com/wio/toolchain:4d5e6f"},
+		{"github.com/wio/toolchain:dev", "github.com/wio/toolchain:dev"},
+		{"https://github.com/wio/toolchain", "https://github.com/wio/toolchain"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.locked, lockedLink(test.link, lock), test.link)
+	}
+
+	// nothing is replaced without a lock entry
+	for _, test := range tests {
+		assert.Equal(t, test.link, lockedLink(test.link, &types.LockImpl{}), test.link)
+	}
+}
@@ -0,0 +1,202 @@
+// Part of validate package, which checks wio.yml against the config types
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"wio/internal/cmd/run/cmake"
+	"wio/internal/config/meta"
+	"wio/internal/constants"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+
+	"github.com/urfave/cli"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var supportedPlatforms = map[string]bool{
+	constants.Avr:    true,
+	constants.Native: true,
+}
+
+type Validate struct {
+	Context *cli.Context
+}
+
+// get context for the command
+func (validate Validate) GetContext() *cli.Context {
+	return validate.Context
+}
+
+// Runs the validate command
+func (validate Validate) Execute() error {
+	if validate.Context.Bool("schema") {
+		data, err := json.MarshalIndent(types.ConfigSchema(), "", "  ")
+		if err != nil {
+			return err
+		}
+		log.Info("%s\n", string(data))
+		return nil
+	}
+
+	directory, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	log.Info(log.Cyan, "validating %s ... ", sys.Config)
+	problems, err := validateConfig(directory)
+	if err != nil {
+		log.WriteFailure()
+		return err
+	}
+	if len(problems) > 0 {
+		log.WriteFailure()
+		for _, problem := range problems {
+			log.Errln("%s:%s", sys.Config, problem.Error())
+		}
+		return util.Error("%s has %d problem(s)", sys.Config, len(problems))
+	}
+
+	log.WriteSuccess()
+	log.Infoln(log.Green, "%s is valid", sys.Config)
+	return nil
+}
+
+// Checks wio.yml as it is written and then checks the values that depend on each other once
+// targets and variables are resolved. Problems from both passes are reported together
+func validateConfig(directory string) ([]types.ConfigError, error) {
+	document, err := types.ReadWioDocument(directory, false)
+	if err != nil {
+		return nil, err
+	}
+	problems := types.CheckWioDocument(document)
+
+	resolved, err := types.ReadWioDocument(directory, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := types.ResolveWioDocument(resolved, directory); err != nil {
+		configErr, positioned := err.(types.ConfigError)
+		if !positioned {
+			configErr = types.ConfigError{Line: 1, Column: 1, Message: err.Error()}
+		}
+		problems = append(problems, configErr)
+	} else {
+		problems = append(problems, types.CheckResolvedDocument(resolved)...)
+		problems = append(problems, checkResolved(resolved)...)
+	}
+	return sortProblems(problems), nil
+}
+
+// Checks values of a resolved document that the config types cannot express
+func checkResolved(document *yamlv3.Node) []types.ConfigError {
+	var problems []types.ConfigError
+	report := func(node *yamlv3.Node, format string, args ...interface{}) {
+		problems = append(problems, types.ConfigError{
+			Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if projectType := types.FindNode(document, "type"); projectType != nil &&
+		projectType.Value != constants.App && projectType.Value != constants.Pkg {
+		report(projectType, "type must be %s or %s", constants.App, constants.Pkg)
+	}
+
+	if project := types.FindNode(document, "project"); project != nil && project.Kind == yamlv3.MappingNode {
+		if wioVersion := types.FindNode(document, "project", "compile_options", "wio_version"); wioVersion == nil {
+			report(project, "project is missing compile_options.wio_version")
+		} else if version := semver.Parse(wioVersion.Value); version == nil {
+			report(wioVersion, "wio_version %s is not a valid version", wioVersion.Value)
+		} else if version.GT(*semver.Parse(meta.Version)) {
+			report(wioVersion, "wio_version %s is newer than current wio version %s", wioVersion.Value, meta.Version)
+		}
+	}
+
+	if dependencies := types.FindNode(document, "dependencies"); dependencies != nil &&
+		dependencies.Kind == yamlv3.MappingNode {
+		for i := 0; i+1 < len(dependencies.Content); i += 2 {
+			name, dependency := dependencies.Content[i], dependencies.Content[i+1]
+			if dependency.Kind != yamlv3.MappingNode {
+				continue
+			}
+			hasSource := false
+			for _, source := range []string{"version", "path", "git", "url"} {
+				hasSource = hasSource || types.FindNode(dependency, source) != nil
+			}
+			if !hasSource {
+				report(name, "dependency %s needs a version, path, git or url", name.Value)
+			}
+		}
+	}
+
+	targets := types.FindNode(document, "targets")
+	names := map[string]bool{}
+	if targets != nil {
+		for i := 0; i+1 < len(targets.Content); i += 2 {
+			name, target := targets.Content[i], targets.Content[i+1]
+			names[name.Value] = true
+			if matrix := types.FindNode(target, "matrix"); matrix != nil {
+				names[matrix.Value] = true
+			}
+
+			platform := types.FindNode(target, "platform")
+			if platform == nil {
+				report(name, "target %s has no platform", name.Value)
+			} else if !supportedPlatforms[platform.Value] {
+				report(platform, "target %s has unsupported platform %s, supported platforms are %s",
+					name.Value, platform.Value, supportedList())
+			} else if platform.Value == constants.Avr && types.FindNode(target, "board") == nil {
+				report(name, "target %s is for avr and needs a board", name.Value)
+			}
+		}
+	}
+
+	if defaultTarget := types.FindNode(document, "project", "compile_options", "default_target"); defaultTarget != nil &&
+		defaultTarget.Value != "" && !names[defaultTarget.Value] {
+		report(defaultTarget, "default_target %s is not a target", defaultTarget.Value)
+	}
+
+	if standard := types.FindNode(document, "project", "compile_options", "standard"); standard != nil {
+		if _, _, err := cmake.GetStandard(standard.Value); err != nil {
+			report(standard, "%s", err.Error())
+		}
+	}
+
+	return problems
+}
+
+// Sorts problems by position, dropping the ones reported by both passes
+func sortProblems(problems []types.ConfigError) []types.ConfigError {
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+
+	var sorted []types.ConfigError
+	seen := map[types.ConfigError]bool{}
+	for _, problem := range problems {
+		if !seen[problem] {
+			seen[problem] = true
+			sorted = append(sorted, problem)
+		}
+	}
+	return sorted
+}
+
+func supportedList() string {
+	var platforms []string
+	for platform := range supportedPlatforms {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	return "[" + strings.Join(platforms, ", ") + "]"
+}
@@ -0,0 +1,81 @@
+package validate
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"wio/internal/config/meta"
+	"wio/internal/types"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validateText(t *testing.T, text string) []types.ConfigError {
+	dir, err := ioutil.TempDir("", "wio-validate")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(sys.Path(dir, sys.Config), []byte(text), 0644))
+
+	problems, err := validateConfig(dir)
+	assert.NoError(t, err)
+	return problems
+}
+
+func TestValidateConfig(t *testing.T) {
+	problems := validateText(t, `type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.10.0
+    default_target: missing
+targets:
+  main:
+    platform: avr
+    unknown: true
+dependencies:
+  local:
+    path: ../local
+  remote: {}
+`)
+
+	assert.Equal(t, []types.ConfigError{
+		{Line: 6, Column: 21, Message: "default_target missing is not a target"},
+		{Line: 8, Column: 3, Message: "target main is for avr and needs a board"},
+		{Line: 10, Column: 5, Message: "unknown field unknown in targets.main"},
+		{Line: 14, Column: 3, Message: "dependency remote needs a version, path, git or url"},
+	}, problems)
+}
+
+func TestValidateResolveError(t *testing.T) {
+	problems := validateText(t, `type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.10.0
+targets:
+  main:
+    extends: missing
+    platform: native
+    unknown: true
+`)
+
+	assert.Equal(t, []types.ConfigError{
+		{Line: 8, Column: 14, Message: "no target or template named missing"},
+		{Line: 10, Column: 5, Message: "unknown field unknown in targets.main"},
+	}, problems)
+}
+
+func TestValidateVersion(t *testing.T) {
+	problems := validateText(t, `type: lib
+project:
+  name: app
+  compile_options:
+    wio_version: 99.0.0
+`)
+
+	assert.Equal(t, []types.ConfigError{
+		{Line: 1, Column: 7, Message: "type must be app or pkg"},
+		{Line: 5, Column: 18, Message: "wio_version 99.0.0 is newer than current wio version " + meta.Version},
+	}, problems)
+}
@@ -1,6 +1,9 @@
 package defaults
 
-import "wio/internal/constants"
+import (
+	"wio/internal/constants"
+	"wio/internal/types"
+)
 
 type Defaults struct {
 	Keywords []string
@@ -27,3 +30,21 @@ var Pkg = Defaults{
 	Target:   "tests",
 	Source:   "tests",
 }
+
+// Built-in build profiles, profiles in wio.yml with the same name extend these
+var Profiles = map[string]*types.ProfileImpl{
+	"debug": {
+		BuildType: "Debug",
+		Flags:     []string{"-O0", "-g"},
+	},
+	"release": {
+		BuildType:   "Release",
+		Flags:       []string{"-O2"},
+		Definitions: []string{"NDEBUG"},
+	},
+	"minsize": {
+		BuildType:   "MinSizeRel",
+		Flags:       []string{"-Os"},
+		Definitions: []string{"NDEBUG"},
+	},
+}
@@ -2,7 +2,7 @@ package meta
 
 const (
 	Name       = "wio"
-	Version    = "0.10.0"
+	Version    = "0.10.2"
 	Completion = true
 	Copyright  = "Copyright (c) 2018 Waterloop"
 	UsageText  = "C/C++ development environment"
@@ -21,3 +21,15 @@ func GetArch() string {
 func GetMinWioVersion() string {
 	return os.Getenv("CONFIG_MIN_WIO_VER")
 }
+
+func GetRegistry() string {
+	return os.Getenv("WIOREGISTRY")
+}
+
+func GetToken() string {
+	return os.Getenv("WIO_TOKEN")
+}
+
+func GetToolchainMirror() string {
+	return os.Getenv("WIOTOOLCHAINMIRROR")
+}
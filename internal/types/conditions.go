@@ -0,0 +1,223 @@
+package types
+
+import (
+	"regexp"
+	"runtime"
+	"strings"
+	"wio/pkg/util/sys"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const (
+	whenTag   = "when"
+	valueTag  = "value"
+	valuesTag = "values"
+)
+
+// fields that can be used inside of a when condition
+var conditionFields = map[string]bool{
+	"os":        true,
+	"arch":      true,
+	"platform":  true,
+	"board":     true,
+	"framework": true,
+}
+
+// older wio.yml files select values per operating system with $linux(...), $darwin(...) and $windows(...)
+var legacyOSSelectors = regexp.MustCompile(`^\s*(\$(darwin|windows|linux)\([^()]*\)\s*,?\s*)+$`)
+var legacyOSSelector = regexp.MustCompile(`\$(darwin|windows|linux)\(([^()]*)\)`)
+
+// Evaluates a when condition. Every field in the condition has to match and a field matches when the
+// value is equal to one of the values provided. Values starting with ! are negated. Target values
+// that are not known match any condition so that nothing is dropped when no target is being built
+func evaluateCondition(when *yamlv3.Node, targetValues map[string]string) (bool, error) {
+	if when.Kind != yamlv3.MappingNode {
+		return false, nodeError(when, "when must be a map of conditions")
+	}
+
+	values := map[string]string{
+		"os":   sys.GetOS(),
+		"arch": runtime.GOARCH,
+	}
+
+	for i := 0; i+1 < len(when.Content); i += 2 {
+		field, condition := when.Content[i], when.Content[i+1]
+		if !conditionFields[field.Value] {
+			return false, nodeError(field, "unknown condition %s", field.Value)
+		}
+
+		value, known := values[field.Value]
+		if !known && targetValues != nil {
+			value, known = targetValues[field.Value], true
+		}
+		if !known {
+			continue
+		}
+
+		matched, err := matchCondition(condition, value)
+		if err != nil {
+			return false, err
+		} else if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchCondition(condition *yamlv3.Node, value string) (bool, error) {
+	var options []*yamlv3.Node
+	switch condition.Kind {
+	case yamlv3.ScalarNode:
+		options = []*yamlv3.Node{condition}
+	case yamlv3.SequenceNode:
+		options = condition.Content
+	default:
+		return false, nodeError(condition, "condition must be a value or a list of values")
+	}
+
+	matched, hasPositive := false, false
+	for _, option := range options {
+		if option.Kind != yamlv3.ScalarNode {
+			return false, nodeError(option, "condition must be a value or a list of values")
+		}
+		if strings.HasPrefix(option.Value, "!") {
+			if strings.EqualFold(strings.TrimPrefix(option.Value, "!"), value) {
+				return false, nil
+			}
+		} else {
+			hasPositive = true
+			matched = matched || strings.EqualFold(option.Value, value)
+		}
+	}
+	return matched || !hasPositive, nil
+}
+
+// Checks for the { when: ..., value: ... } and { when: ..., values: [...] } forms
+func conditionalValue(node *yamlv3.Node) (*yamlv3.Node, bool) {
+	if node.Kind != yamlv3.MappingNode || len(node.Content) != 4 || mappingValue(node, whenTag) == nil {
+		return nil, false
+	}
+	if value := mappingValue(node, valueTag); value != nil {
+		return value, false
+	}
+	if values := mappingValue(node, valuesTag); values != nil {
+		return values, true
+	}
+	return nil, false
+}
+
+// Selects value for the current operating system from $linux(...), $darwin(...) and $windows(...)
+func resolveLegacyOS(node *yamlv3.Node) (keep bool) {
+	if node.Kind != yamlv3.ScalarNode || !legacyOSSelectors.MatchString(node.Value) {
+		return true
+	}
+	for _, match := range legacyOSSelector.FindAllStringSubmatch(node.Value, -1) {
+		if match[1] == sys.GetOS() {
+			node.Value = strings.TrimSpace(match[2])
+			if node.Style == 0 {
+				node.Tag = ""
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluates conditions of the values of a mapping or the items of a sequence. Values that do not
+// match are removed and conditional values are replaced by their value
+func (interp *interpolator) resolveConditions(node *yamlv3.Node, scope *interpolateScope) error {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		content := make([]*yamlv3.Node, 0, len(node.Content))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			replaced, _ := conditionalValue(value)
+			keep, err := interp.resolveCondition(value, scope)
+			if err != nil {
+				return err
+			} else if !keep {
+				continue
+			}
+			if replaced != nil {
+				value = replaced
+			}
+			content = append(content, key, value)
+		}
+		node.Content = content
+
+	case yamlv3.SequenceNode:
+		content := make([]*yamlv3.Node, 0, len(node.Content))
+		for _, item := range node.Content {
+			replaced, splice := conditionalValue(item)
+			keep, err := interp.resolveCondition(item, scope)
+			if err != nil {
+				return err
+			} else if !keep {
+				continue
+			}
+			if replaced != nil && splice {
+				if replaced.Kind != yamlv3.SequenceNode {
+					return nodeError(replaced, "values must be a list")
+				}
+				content = append(content, replaced.Content...)
+			} else if replaced != nil {
+				content = append(content, replaced)
+			} else {
+				content = append(content, item)
+			}
+		}
+		node.Content = content
+	}
+	return nil
+}
+
+// Evaluates the when condition of a node and removes it once it is evaluated
+func (interp *interpolator) resolveCondition(node *yamlv3.Node, scope *interpolateScope) (bool, error) {
+	if !resolveLegacyOS(node) {
+		return false, nil
+	}
+
+	when := mappingValue(node, whenTag)
+	if when == nil {
+		return true, nil
+	}
+	if err := interp.interpolateNode(when, scope); err != nil {
+		return false, err
+	}
+	matched, err := evaluateCondition(when, scope.conditions)
+	if err != nil || !matched {
+		return false, err
+	}
+	deleteMappingKey(node, whenTag)
+	return true, nil
+}
+
+// Replaces conditional values with their value and removes when conditions without evaluating
+// them, so that wio.yml can be decoded as it is written
+func flattenConditions(node *yamlv3.Node) {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		deleteMappingKey(node, whenTag)
+		for i := 1; i < len(node.Content); i += 2 {
+			if value, _ := conditionalValue(node.Content[i]); value != nil {
+				node.Content[i] = value
+			}
+		}
+	case yamlv3.SequenceNode:
+		content := make([]*yamlv3.Node, 0, len(node.Content))
+		for _, item := range node.Content {
+			if value, splice := conditionalValue(item); value != nil && splice && value.Kind == yamlv3.SequenceNode {
+				content = append(content, value.Content...)
+			} else if value != nil {
+				content = append(content, value)
+			} else {
+				content = append(content, item)
+			}
+		}
+		node.Content = content
+	}
+	for _, child := range node.Content {
+		flattenConditions(child)
+	}
+}
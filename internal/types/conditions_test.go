@@ -0,0 +1,182 @@
+package types
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// operating system that tests are not running on
+func otherOS() string {
+	if sys.GetOS() == sys.WINDOWS {
+		return sys.LINUX
+	}
+	return sys.WINDOWS
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	avr := map[string]string{"platform": "avr", "board": "uno", "framework": "arduino"}
+	tests := []struct {
+		name    string
+		when    string
+		target  map[string]string
+		matched bool
+	}{
+		{"current os", "os: " + sys.GetOS(), nil, true},
+		{"other os", "os: " + otherOS(), nil, false},
+		{"os list", "os: [" + otherOS() + ", " + sys.GetOS() + "]", nil, true},
+		{"arch", "arch: " + runtime.GOARCH, nil, true},
+		{"case insensitive", "platform: AVR", avr, true},
+		{"every field", "{platform: avr, board: mega2560}", avr, false},
+		{"negated", "board: '!uno'", avr, false},
+		{"negated other", "board: '!mega2560'", avr, true},
+		{"negated list", "board: ['!mega2560', '!nano']", avr, true},
+		{"negated and positive", "board: ['!nano', uno]", avr, true},
+		{"negated os", "os: '!" + sys.GetOS() + "'", nil, false},
+		{"no target context", "{platform: native, board: mega2560}", nil, true},
+		{"unknown target value", "framework: cosa", map[string]string{"platform": "native"}, false},
+	}
+
+	for _, test := range tests {
+		when := &yamlv3.Node{}
+		assert.NoError(t, yamlv3.Unmarshal([]byte(test.when), when), test.name)
+		matched, err := evaluateCondition(documentRoot(when), test.target)
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.matched, matched, test.name)
+	}
+}
+
+func TestEvaluateConditionErrors(t *testing.T) {
+	tests := []struct {
+		when    string
+		message string
+	}{
+		{"[linux]", "1:1: when must be a map of conditions"},
+		{"compiler: gcc", "1:1: unknown condition compiler"},
+		{"board: {name: uno}", "1:8: condition must be a value or a list of values"},
+		{"board: [[uno]]", "1:9: condition must be a value or a list of values"},
+	}
+
+	for _, test := range tests {
+		when := &yamlv3.Node{}
+		assert.NoError(t, yamlv3.Unmarshal([]byte(test.when), when))
+		_, err := evaluateCondition(documentRoot(when), map[string]string{"board": "uno"})
+		assert.EqualError(t, err, test.message)
+	}
+}
+
+func TestConditionalValues(t *testing.T) {
+	dir := writeTestConfig(t, `type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.10.0
+    standard: {when: {platform: avr}, value: c++11}
+    flags:
+    - -Wall
+    - {when: {os: `+otherOS()+`}, value: -Wother}
+    - {when: {os: `+sys.GetOS()+`}, values: [-Wcurrent, -Wextra]}
+    - {when: {board: '!uno'}, value: -DNOT_UNO}
+targets:
+  main:
+    src: src
+    platform: avr
+    board: uno
+    linker_flags:
+    - {when: {board: uno}, value: -luno}
+    - {when: {board: '!uno'}, value: -lother}
+  native:
+    when: {os: `+otherOS()+`}
+    platform: native
+`)
+	defer os.RemoveAll(dir)
+
+	config, err := ReadWioConfig(dir, true)
+	assert.NoError(t, err)
+	options := config.GetInfo().GetOptions()
+	// values outside of targets are kept for every target when no target is being built
+	assert.Equal(t, "c++11", options.GetStandard())
+	assert.Equal(t, []string{"-Wall", "-Wcurrent", "-Wextra", "-DNOT_UNO"}, options.GetFlags())
+	assert.Equal(t, []string{"-luno"}, config.GetTargets()["main"].GetLinkerFlags())
+	assert.NotContains(t, config.GetTargets(), "native")
+
+	config, err = ReadTargetConfig(dir, config.GetTargets()["main"])
+	assert.NoError(t, err)
+	assert.Equal(t, "c++11", config.GetInfo().GetOptions().GetStandard())
+	assert.Equal(t, []string{"-Wall", "-Wcurrent", "-Wextra"}, config.GetInfo().GetOptions().GetFlags())
+
+	config, err = ReadTargetConfig(dir, &TargetImpl{Platform: "native"})
+	assert.NoError(t, err)
+	assert.Equal(t, "", config.GetInfo().GetOptions().GetStandard())
+	assert.Equal(t, []string{"-Wall", "-Wcurrent", "-Wextra", "-DNOT_UNO"}, config.GetInfo().GetOptions().GetFlags())
+
+	// unresolved configs keep every conditional value
+	config, err = ReadWioConfig(dir, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-Wall", "-Wother", "-Wcurrent", "-Wextra", "-DNOT_UNO"},
+		config.GetInfo().GetOptions().GetFlags())
+}
+
+func TestLegacyOSSelectors(t *testing.T) {
+	tests := []struct {
+		value string
+		keep  bool
+		text  string
+	}{
+		{"$" + sys.GetOS() + "(-lcurrent)", true, "-lcurrent"},
+		{"$" + otherOS() + "(-lother)", false, ""},
+		{"$" + otherOS() + "(-lother), $" + sys.GetOS() + "( -lcurrent )", true, "-lcurrent"},
+		{"-lm", true, "-lm"},
+		{"prefix $" + sys.GetOS() + "(-lcurrent)", true, "prefix $" + sys.GetOS() + "(-lcurrent)"},
+	}
+
+	for _, test := range tests {
+		node := &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: test.value}
+		assert.Equal(t, test.keep, resolveLegacyOS(node), test.value)
+		if test.keep {
+			assert.Equal(t, test.text, node.Value, test.value)
+		}
+	}
+}
+
+// yaml.v3 reads yes, no, on and off as strings, they are still read as booleans in boolean fields
+func TestLegacyBooleans(t *testing.T) {
+	dir := writeTestConfig(t, `type: pkg
+project:
+  name: pkg
+  compile_options:
+    wio_version: 0.9.0
+    header_only: yes
+dependencies:
+  fmt:
+    version: ^1.0.0
+    vendor: on
+  log:
+    version: ^1.0.0
+    vendor: no
+libraries:
+  zlib:
+    cmake_package: On
+    variables:
+      ZLIB_STATIC: yes
+`)
+	defer os.RemoveAll(dir)
+
+	for _, fulfill := range []bool{false, true} {
+		config, err := ReadWioConfig(dir, fulfill)
+		assert.NoError(t, err)
+		assert.True(t, config.GetInfo().GetOptions().GetIsHeaderOnly())
+		assert.True(t, config.GetDependencies()["fmt"].IsVendor())
+		assert.False(t, config.GetDependencies()["log"].IsVendor())
+		assert.True(t, config.GetLibraries()["zlib"].IsCmakePackage())
+		assert.Equal(t, "yes", config.GetLibraries()["zlib"].GetVariables()["ZLIB_STATIC"])
+	}
+
+	document, err := ReadWioDocument(dir, false)
+	assert.NoError(t, err)
+	assert.Empty(t, CheckWioDocument(document))
+}
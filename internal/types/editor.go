@@ -0,0 +1,398 @@
+package types
+
+import (
+	"bytes"
+	"strings"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Edits wio.yml in place. Only the entries that are set or deleted are rewritten and everything
+// else including comments, blank lines and order of keys is kept as it is
+type ConfigEditor struct {
+	path     string
+	newline  string
+	lines    []string
+	document *yamlv3.Node
+}
+
+// Opens wio.yml of a project for editing
+func EditWioConfig(dir string) (*ConfigEditor, error) {
+	path := sys.Path(dir, sys.Config)
+	if !sys.Exists(path) {
+		return nil, util.Error("path does not contain a wio.yml: %s", dir)
+	}
+	text, err := sys.NormalIO.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	editor := &ConfigEditor{path: path, newline: "\n"}
+	if bytes.Contains(text, []byte("\r\n")) {
+		editor.newline = "\r\n"
+	}
+	return editor, editor.parse(string(text))
+}
+
+// Text of wio.yml with all the edits
+func (editor *ConfigEditor) Text() []byte {
+	return []byte(strings.Join(editor.lines, ""))
+}
+
+// Checks edited wio.yml against the config types
+func (editor *ConfigEditor) Check() []ConfigError {
+	return CheckWioDocument(editor.document)
+}
+
+// Writes edited wio.yml
+func (editor *ConfigEditor) Save() error {
+	return sys.NormalIO.WriteFile(editor.path, editor.Text())
+}
+
+// Sets value at the path of keys. Maps that do not exist yet are created
+func (editor *ConfigEditor) Set(value interface{}, keys ...string) error {
+	node := &yamlv3.Node{}
+	if err := node.Encode(value); err != nil {
+		return err
+	}
+	return editor.SetNode(node, keys...)
+}
+
+// Sets node at the path of keys. Maps that do not exist yet are created
+func (editor *ConfigEditor) SetNode(node *yamlv3.Node, keys ...string) error {
+	if len(keys) <= 0 {
+		return util.Error("no key provided to set")
+	}
+
+	root := documentRoot(editor.document)
+	if root == nil || (root.Kind == yamlv3.ScalarNode && root.ShortTag() == "!!null") {
+		entry, err := editor.renderEntry("", keys[0], nestedNode(keys[1:], node))
+		if err != nil {
+			return err
+		}
+		return editor.insertLines(len(editor.lines), entry)
+	} else if root.Kind != yamlv3.MappingNode {
+		return util.Error("%s must be a map", sys.Config)
+	}
+
+	var ownerKey *yamlv3.Node
+	parent := root
+	for i, key := range keys {
+		if parent.Style&yamlv3.FlowStyle != 0 {
+			if ownerKey == nil {
+				return util.Error("%s must be written in block style to be edited", sys.Config)
+			}
+			// flow maps are rewritten as a whole
+			if err := setNodePath(parent, keys[i:], node); err != nil {
+				return err
+			}
+			return editor.replaceEntry(ownerKey, mappingValue(editor.parentOf(ownerKey), ownerKey.Value), nil)
+		}
+
+		keyNode, valueNode := mappingEntry(parent, key)
+		if keyNode == nil {
+			return editor.insertEntry(parent, key, nestedNode(keys[i+1:], node))
+		} else if i == len(keys)-1 {
+			return editor.replaceEntry(keyNode, valueNode, node)
+		}
+
+		if valueNode.Kind == yamlv3.ScalarNode && valueNode.ShortTag() == "!!null" {
+			return editor.replaceEntry(keyNode, valueNode, nestedNode(keys[i+1:], node))
+		} else if valueNode.Kind != yamlv3.MappingNode {
+			return util.Error("line %d: %s must be a map", valueNode.Line, strings.Join(keys[:i+1], "."))
+		}
+		ownerKey, parent = keyNode, valueNode
+	}
+	return nil
+}
+
+// Deletes the value at the path of keys if it exists
+func (editor *ConfigEditor) Delete(keys ...string) error {
+	var ownerKey *yamlv3.Node
+	parent := documentRoot(editor.document)
+	for i, key := range keys {
+		keyNode, valueNode := mappingEntry(parent, key)
+		if keyNode == nil {
+			return nil
+		}
+		if i < len(keys)-1 {
+			ownerKey, parent = keyNode, valueNode
+			continue
+		}
+
+		if parent.Style&yamlv3.FlowStyle != 0 && ownerKey != nil {
+			deleteMappingKey(parent, key)
+			return editor.replaceEntry(ownerKey, mappingValue(editor.parentOf(ownerKey), ownerKey.Value), nil)
+		}
+
+		// maps left empty are removed along with their key
+		if len(parent.Content) <= 2 && ownerKey != nil {
+			return editor.Delete(keys[:i]...)
+		}
+
+		start, end := keyNode.Line-1, editor.entryEnd(keyNode, valueNode)
+		if keyNode.HeadComment != "" {
+			comments := strings.Count(keyNode.HeadComment, "\n") + 1
+			for comments > 0 && start > 0 && strings.HasPrefix(strings.TrimSpace(editor.lines[start-1]), "#") {
+				start--
+				comments--
+			}
+		}
+		// top level sections take the blank line separating them along
+		blank := func(i int) bool { return i < 0 || i >= len(editor.lines) || strings.TrimSpace(editor.lines[i]) == "" }
+		if parent == documentRoot(editor.document) && start > 0 && blank(start-1) && blank(end) {
+			start--
+		}
+		editor.lines = append(editor.lines[:start], editor.lines[end:]...)
+		return editor.parse(strings.Join(editor.lines, ""))
+	}
+	return nil
+}
+
+func (editor *ConfigEditor) parse(text string) error {
+	document := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal([]byte(text), document); err != nil {
+		return util.Error("%s: %s", editor.path, err.Error())
+	}
+	editor.document = document
+	editor.lines = strings.SplitAfter(text, "\n")
+	if len(editor.lines) > 0 && editor.lines[len(editor.lines)-1] == "" {
+		editor.lines = editor.lines[:len(editor.lines)-1]
+	}
+	return nil
+}
+
+// Finds the mapping that holds a key
+func (editor *ConfigEditor) parentOf(key *yamlv3.Node) *yamlv3.Node {
+	var find func(node *yamlv3.Node) *yamlv3.Node
+	find = func(node *yamlv3.Node) *yamlv3.Node {
+		for i, child := range node.Content {
+			if child == key && node.Kind == yamlv3.MappingNode && i%2 == 0 {
+				return node
+			}
+			if parent := find(child); parent != nil {
+				return parent
+			}
+		}
+		return nil
+	}
+	return find(editor.document)
+}
+
+// Replaces lines of an entry with the entry rendered using the new value. When value is nil the
+// current value is rendered, this is used to rewrite flow maps that were changed
+func (editor *ConfigEditor) replaceEntry(key *yamlv3.Node, current *yamlv3.Node, value *yamlv3.Node) error {
+	start, end := key.Line-1, editor.entryEnd(key, current)
+	if value == nil {
+		value = current
+	}
+
+	line := editor.lines[start]
+	prefix := line[:key.Column-1]
+	entry, err := editor.renderEntry(prefix, key.Value, value)
+	if err != nil {
+		return err
+	}
+
+	// keep comment written after a value on the same line
+	comment := key.LineComment
+	if comment == "" && current.Line == key.Line {
+		comment = current.LineComment
+	}
+	if comment != "" && value.LineComment == "" {
+		entry[0] = strings.TrimSuffix(entry[0], editor.newline) + " " + comment + editor.newline
+	}
+
+	editor.lines = append(editor.lines[:start], append(entry, editor.lines[end:]...)...)
+	return editor.parse(strings.Join(editor.lines, ""))
+}
+
+// Adds an entry after the last entry of a block mapping
+func (editor *ConfigEditor) insertEntry(parent *yamlv3.Node, key string, value *yamlv3.Node) error {
+	lastKey, lastValue := parent.Content[len(parent.Content)-2], parent.Content[len(parent.Content)-1]
+	position := editor.entryEnd(lastKey, lastValue)
+	entry, err := editor.renderEntry(strings.Repeat(" ", lastKey.Column-1), key, value)
+	if err != nil {
+		return err
+	}
+
+	// top level sections are separated by a blank line
+	if parent == documentRoot(editor.document) {
+		entry = append([]string{editor.newline}, entry...)
+	}
+	return editor.insertLines(position, entry)
+}
+
+func (editor *ConfigEditor) insertLines(position int, entry []string) error {
+	if position > 0 && !strings.HasSuffix(editor.lines[position-1], "\n") {
+		editor.lines[position-1] += editor.newline
+	}
+	editor.lines = append(editor.lines[:position], append(entry, editor.lines[position:]...)...)
+	return editor.parse(strings.Join(editor.lines, ""))
+}
+
+// Finds the line after the last line of an entry. Blank lines and comments at the end of the entry
+// are left out since they usually belong to what comes next
+func (editor *ConfigEditor) entryEnd(key *yamlv3.Node, value *yamlv3.Node) int {
+	indent := key.Column - 1
+	compactSequence := value.Kind == yamlv3.SequenceNode && value.Style&yamlv3.FlowStyle == 0
+
+	end := key.Line
+	for i := key.Line; i < len(editor.lines); i++ {
+		content := strings.TrimLeft(editor.lines[i], " ")
+		if strings.TrimSpace(content) == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+		lineIndent := len(editor.lines[i]) - len(content)
+		if lineIndent > indent || (compactSequence && lineIndent == indent && strings.HasPrefix(content, "-")) {
+			end = i + 1
+			continue
+		}
+		break
+	}
+	return end
+}
+
+// Renders key and value as lines of wio.yml. Maps are indented under their key and lists are
+// written at the indentation of their key
+func (editor *ConfigEditor) renderEntry(prefix string, key string, value *yamlv3.Node) ([]string, error) {
+	keyLines, err := renderNode(&yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key})
+	if err != nil {
+		return nil, err
+	}
+	lines, err := renderNode(value)
+	if err != nil {
+		return nil, err
+	}
+	keyText := keyLines[0]
+	indent := strings.Repeat(" ", len(prefix))
+
+	var entry []string
+	block := (value.Kind == yamlv3.MappingNode || value.Kind == yamlv3.SequenceNode) &&
+		value.Style&yamlv3.FlowStyle == 0 && len(value.Content) > 0
+	if !block {
+		entry = append(entry, prefix+keyText+": "+lines[0]+editor.newline)
+		for _, line := range lines[1:] {
+			entry = append(entry, indent+line+editor.newline)
+		}
+		return entry, nil
+	}
+
+	if value.Kind == yamlv3.MappingNode {
+		indent += "  "
+	}
+	entry = append(entry, prefix+keyText+":"+editor.newline)
+	for _, line := range lines {
+		entry = append(entry, indent+line+editor.newline)
+	}
+	return entry, nil
+}
+
+func renderNode(node *yamlv3.Node) ([]string, error) {
+	text, err := EncodeNode(node)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(string(text), "\n"), "\n"), nil
+}
+
+// Encodes a node the way wio writes wio.yml
+func EncodeNode(node *yamlv3.Node) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	encoder := yamlv3.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	lines := compactSequences(strings.Split(buffer.String(), "\n"))
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// Sets value inside of a mapping node at the path of keys
+func setNodePath(node *yamlv3.Node, keys []string, value *yamlv3.Node) error {
+	for i, key := range keys[:len(keys)-1] {
+		child := mappingValue(node, key)
+		if child == nil || (child.Kind == yamlv3.ScalarNode && child.ShortTag() == "!!null") {
+			setMappingValue(node, key, nestedNode(keys[i+1:], value))
+			return nil
+		} else if child.Kind != yamlv3.MappingNode {
+			return util.Error("line %d: %s must be a map", child.Line, key)
+		}
+		node = child
+	}
+	setMappingValue(node, keys[len(keys)-1], value)
+	return nil
+}
+
+// Wraps value in maps for each of the keys
+func nestedNode(keys []string, value *yamlv3.Node) *yamlv3.Node {
+	for i := len(keys) - 1; i >= 0; i-- {
+		value = &yamlv3.Node{
+			Kind: yamlv3.MappingNode,
+			Tag:  "!!map",
+			Content: []*yamlv3.Node{
+				{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: keys[i]},
+				value,
+			},
+		}
+	}
+	return value
+}
+
+// Finds key and value of an entry inside of a mapping node
+func mappingEntry(node *yamlv3.Node, key string) (*yamlv3.Node, *yamlv3.Node) {
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// yaml.v3 indents lists inside of a map while wio writes them at the indentation of their key.
+// Comments are moved along with the line that follows them
+func compactSequences(lines []string) []string {
+	var sequences, comments []int
+	previous := -1
+	shift := func(i int, count int) {
+		if strings.HasPrefix(lines[i], strings.Repeat(" ", count)) {
+			lines[i] = lines[i][count:]
+		}
+	}
+
+	for i, line := range lines {
+		content := strings.TrimLeft(line, " ")
+		if content == "" {
+			continue
+		} else if strings.HasPrefix(content, "#") {
+			comments = append(comments, i)
+			continue
+		}
+
+		indent := len(line) - len(content)
+		for len(sequences) > 0 && indent < sequences[len(sequences)-1] {
+			sequences = sequences[:len(sequences)-1]
+		}
+		if (content == "-" || strings.HasPrefix(content, "- ")) && indent > previous && previous >= 0 {
+			sequences = append(sequences, indent)
+		}
+		previous = indent
+
+		for _, comment := range append(comments, i) {
+			shift(comment, 2*len(sequences))
+		}
+		comments = comments[:0]
+	}
+	for _, comment := range comments {
+		shift(comment, 2*len(sequences))
+	}
+	return lines
+}
@@ -0,0 +1,204 @@
+package types
+
+import (
+	"os"
+	"testing"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const editorFixture = `# project settings
+type: app
+
+project:
+  name: app # the name
+  version: 0.0.1
+  compile_options:
+    wio_version: 0.10.0
+
+# build targets
+targets:
+  main:
+    src: src
+    platform: native
+    flags:
+      global:
+      - -Wall
+
+# packages
+dependencies:
+  # used for printing
+  fmt:
+    version: ^1.0.0
+  log: {version: ^2.0.0, vendor: true}
+`
+
+func editFixture(t *testing.T, text string) (*ConfigEditor, string) {
+	dir := writeTestConfig(t, text)
+	editor, err := EditWioConfig(dir)
+	assert.NoError(t, err)
+	return editor, dir
+}
+
+func TestEditorSet(t *testing.T) {
+	editor, dir := editFixture(t, editorFixture)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, editor.Set("0.0.2", "project", "version"))
+	assert.NoError(t, editor.Set("my-app", "project", "name"))
+	assert.NoError(t, editor.Set("gnu++14", "project", "compile_options", "standard"))
+	assert.NoError(t, editor.Set(map[string]string{"version": "^3.0.0"}, "dependencies", "json"))
+	assert.NoError(t, editor.Set("PRIVATE", "dependencies", "log", "link_visibility"))
+	assert.NoError(t, editor.Set([]string{"-Wall", "-Werror"}, "targets", "main", "flags", "global"))
+
+	assert.Equal(t, `# project settings
+type: app
+
+project:
+  name: my-app # the name
+  version: 0.0.2
+  compile_options:
+    wio_version: 0.10.0
+    standard: gnu++14
+
+# build targets
+targets:
+  main:
+    src: src
+    platform: native
+    flags:
+      global:
+      - -Wall
+      - -Werror
+
+# packages
+dependencies:
+  # used for printing
+  fmt:
+    version: ^1.0.0
+  log: {version: ^2.0.0, vendor: true, link_visibility: PRIVATE}
+  json:
+    version: ^3.0.0
+`, string(editor.Text()))
+	assert.Empty(t, editor.Check())
+}
+
+func TestEditorSetSection(t *testing.T) {
+	editor, dir := editFixture(t, editorFixture)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, editor.Set("value", "variables", "name"))
+
+	assert.Equal(t, editorFixture+`
+variables:
+  name: value
+`, string(editor.Text()))
+}
+
+func TestEditorSetNode(t *testing.T) {
+	editor, dir := editFixture(t, editorFixture)
+	defer os.RemoveAll(dir)
+
+	node := &yamlv3.Node{}
+	assert.NoError(t, yamlv3.Unmarshal([]byte("platform: avr\nboard: uno\n"), node))
+	assert.NoError(t, editor.SetNode(node.Content[0], "targets", "avr"))
+
+	assert.Equal(t, `# project settings
+type: app
+
+project:
+  name: app # the name
+  version: 0.0.1
+  compile_options:
+    wio_version: 0.10.0
+
+# build targets
+targets:
+  main:
+    src: src
+    platform: native
+    flags:
+      global:
+      - -Wall
+  avr:
+    platform: avr
+    board: uno
+
+# packages
+dependencies:
+  # used for printing
+  fmt:
+    version: ^1.0.0
+  log: {version: ^2.0.0, vendor: true}
+`, string(editor.Text()))
+
+	assert.Error(t, editor.SetNode(node.Content[0], "type", "name"))
+	assert.Error(t, editor.SetNode(node.Content[0]))
+}
+
+func TestEditorSetNodeEncodeError(t *testing.T) {
+	editor, dir := editFixture(t, editorFixture)
+	defer os.RemoveAll(dir)
+	invalid := &yamlv3.Node{Kind: yamlv3.AliasNode, Value: "not an anchor"}
+	assert.Error(t, editor.SetNode(invalid, "project", "version"))
+	assert.Error(t, editor.SetNode(invalid, "project", "license"))
+	assert.Error(t, editor.SetNode(invalid, "variables"))
+	assert.Equal(t, editorFixture, string(editor.Text()))
+}
+
+func TestEditorDelete(t *testing.T) {
+	editor, dir := editFixture(t, editorFixture)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, editor.Delete("dependencies", "fmt"))
+	assert.NoError(t, editor.Delete("dependencies", "log", "vendor"))
+	assert.NoError(t, editor.Delete("targets", "main", "flags", "global"))
+	assert.NoError(t, editor.Delete("project", "missing"))
+
+	assert.Equal(t, `# project settings
+type: app
+
+project:
+  name: app # the name
+  version: 0.0.1
+  compile_options:
+    wio_version: 0.10.0
+
+# build targets
+targets:
+  main:
+    src: src
+    platform: native
+
+# packages
+dependencies:
+  log: {version: ^2.0.0}
+`, string(editor.Text()))
+
+	assert.NoError(t, editor.Delete("targets"))
+	assert.Equal(t, `# project settings
+type: app
+
+project:
+  name: app # the name
+  version: 0.0.1
+  compile_options:
+    wio_version: 0.10.0
+
+# packages
+dependencies:
+  log: {version: ^2.0.0}
+`, string(editor.Text()))
+}
+
+func TestEditorSave(t *testing.T) {
+	dir := writeTestConfig(t, "type: app\r\nproject:\r\n  name: app\r\n")
+	defer os.RemoveAll(dir)
+	editor, err := EditWioConfig(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, editor.Set("0.0.1", "project", "version"))
+	assert.NoError(t, editor.Save())
+
+	text, err := sys.NormalIO.ReadFile(sys.Path(dir, sys.Config))
+	assert.NoError(t, err)
+	assert.Equal(t, "type: app\r\nproject:\r\n  name: app\r\n  version: 0.0.1\r\n", string(text))
+}
@@ -27,14 +27,114 @@ func (p *PropertiesImpl) GetPackage() []string {
 	return p.Package
 }
 
+type RunImpl struct {
+	Args       []string          `yaml:"args,omitempty"`
+	Env        map[string]string `yaml:"env,omitempty"`
+	WorkingDir string            `yaml:"working_dir,omitempty"`
+	Stdin      string            `yaml:"stdin,omitempty"`
+	Simulator  *SimulatorImpl    `yaml:"simulator,omitempty"`
+}
+
+func (r *RunImpl) GetArgs() []string {
+	if r == nil {
+		return []string{}
+	}
+	return r.Args
+}
+
+func (r *RunImpl) GetEnv() map[string]string {
+	if r == nil {
+		return map[string]string{}
+	}
+	return r.Env
+}
+
+func (r *RunImpl) GetWorkingDir() string {
+	if r == nil {
+		return ""
+	}
+	return r.WorkingDir
+}
+
+func (r *RunImpl) GetStdin() string {
+	if r == nil {
+		return ""
+	}
+	return r.Stdin
+}
+
+func (r *RunImpl) GetSimulator() Simulator {
+	if r == nil {
+		return (*SimulatorImpl)(nil)
+	}
+	return r.Simulator
+}
+
+type SimulatorImpl struct {
+	Command   string `yaml:"command,omitempty"`
+	Mcu       string `yaml:"mcu,omitempty"`
+	Frequency uint64 `yaml:"frequency,omitempty"`
+	Timeout   string `yaml:"timeout,omitempty"`
+	ExitOn    string `yaml:"exit_on,omitempty"`
+	FailOn    string `yaml:"fail_on,omitempty"`
+}
+
+func (s *SimulatorImpl) GetCommand() string {
+	if s == nil {
+		return ""
+	}
+	return s.Command
+}
+
+func (s *SimulatorImpl) GetMcu() string {
+	if s == nil {
+		return ""
+	}
+	return s.Mcu
+}
+
+func (s *SimulatorImpl) GetFrequency() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.Frequency
+}
+
+func (s *SimulatorImpl) GetTimeout() string {
+	if s == nil {
+		return ""
+	}
+	return s.Timeout
+}
+
+func (s *SimulatorImpl) GetExitOn() string {
+	if s == nil {
+		return ""
+	}
+	return s.ExitOn
+}
+
+func (s *SimulatorImpl) GetFailOn() string {
+	if s == nil {
+		return ""
+	}
+	return s.FailOn
+}
+
 type TargetImpl struct {
-	Source      string          `yaml:"src"`
-	Platform    string          `yaml:"platform,omitempty"`
-	Framework   string          `yaml:"framework,omitempty"`
-	Board       string          `yaml:"board,omitempty"`
-	Flags       *PropertiesImpl `yaml:"flags,omitempty"`
-	Definitions *PropertiesImpl `yaml:"definitions,omitempty"`
-	LinkerFlags []string        `yaml:"linker_flags,omitempty"`
+	Extends     string            `yaml:"extends,omitempty"`
+	Source      string            `yaml:"src"`
+	Platform    string            `yaml:"platform,omitempty"`
+	Framework   string            `yaml:"framework,omitempty"`
+	Board       string            `yaml:"board,omitempty"`
+	Boards      []string          `yaml:"boards,omitempty"`
+	Frameworks  []string          `yaml:"frameworks,omitempty"`
+	Matrix      string            `yaml:"matrix,omitempty" schema:"internal"`
+	Flags       *PropertiesImpl   `yaml:"flags,omitempty"`
+	Definitions *PropertiesImpl   `yaml:"definitions,omitempty"`
+	LinkerFlags []string          `yaml:"linker_flags,omitempty"`
+	Run         *RunImpl          `yaml:"run,omitempty"`
+	Variables   map[string]string `yaml:"variables,omitempty"`
 	name        string
 }
 
@@ -66,6 +166,28 @@ func (t *TargetImpl) GetBoard() string {
 	return t.Board
 }
 
+func (t *TargetImpl) GetBoards() []string {
+	if t == nil {
+		return []string{}
+	}
+	return t.Boards
+}
+
+func (t *TargetImpl) GetFrameworks() []string {
+	if t == nil {
+		return []string{}
+	}
+	return t.Frameworks
+}
+
+// Name of the target this target was expanded from when it is part of a build matrix
+func (t *TargetImpl) GetMatrix() string {
+	if t == nil {
+		return ""
+	}
+	return t.Matrix
+}
+
 func (t *TargetImpl) GetFlags() Properties {
 	return t.Flags
 }
@@ -78,6 +200,10 @@ func (t *TargetImpl) GetLinkerFlags() []string {
 	return t.LinkerFlags
 }
 
+func (t *TargetImpl) GetRun() Run {
+	return t.Run
+}
+
 func (t *TargetImpl) GetName() string {
 	return t.name
 }
@@ -86,6 +212,53 @@ func (t *TargetImpl) SetName(name string) {
 	t.name = name
 }
 
+type ProfileImpl struct {
+	BuildType   string   `yaml:"build_type,omitempty"`
+	Flags       []string `yaml:"flags,omitempty"`
+	Definitions []string `yaml:"definitions,omitempty"`
+	LinkerFlags []string `yaml:"linker_flags,omitempty"`
+	name        string
+}
+
+func (p *ProfileImpl) GetBuildType() string {
+	if p == nil {
+		return ""
+	}
+	return p.BuildType
+}
+
+func (p *ProfileImpl) GetFlags() []string {
+	if p == nil {
+		return []string{}
+	}
+	return p.Flags
+}
+
+func (p *ProfileImpl) GetDefinitions() []string {
+	if p == nil {
+		return []string{}
+	}
+	return p.Definitions
+}
+
+func (p *ProfileImpl) GetLinkerFlags() []string {
+	if p == nil {
+		return []string{}
+	}
+	return p.LinkerFlags
+}
+
+func (p *ProfileImpl) GetName() string {
+	if p == nil {
+		return ""
+	}
+	return p.name
+}
+
+func (p *ProfileImpl) SetName(name string) {
+	p.name = name
+}
+
 type LibraryImpl struct {
 	Package            bool              `yaml:"cmake_package,omitempty"`
 	ImportedTargets    bool              `yaml:"use_imported_targets,omitempty"`
@@ -184,6 +357,11 @@ func (d *DependencyUrlImpl) GetOptions() map[string]string {
 
 type DependencyImpl struct {
 	Url          *DependencyUrlImpl `yaml:"url,omitempty"`
+	Path         string             `yaml:"path,omitempty"`
+	Git          string             `yaml:"git,omitempty"`
+	Ref          string             `yaml:"ref,omitempty"`
+	Tag          string             `yaml:"tag,omitempty"`
+	Commit       string             `yaml:"commit,omitempty"`
 	Vendor       bool               `yaml:"vendor,omitempty"`
 	Version      string             `yaml:"version"`
 	OsSupported  []string           `yaml:"os_supported,omitempty"`
@@ -204,6 +382,26 @@ func (d *DependencyImpl) GetVersion() string {
 	return d.Version
 }
 
+func (d *DependencyImpl) GetPath() string {
+	return d.Path
+}
+
+func (d *DependencyImpl) GetGit() string {
+	return d.Git
+}
+
+func (d *DependencyImpl) GetRef() string {
+	return d.Ref
+}
+
+func (d *DependencyImpl) GetTag() string {
+	return d.Tag
+}
+
+func (d *DependencyImpl) GetCommit() string {
+	return d.Commit
+}
+
 func (d *DependencyImpl) GetVisibility() string {
 	return d.Visibility
 }
@@ -329,7 +527,7 @@ func (d *DefinitionsImpl) GetOptional() DefinitionSet {
 }
 
 type InfoImpl struct {
-	Name    string `yaml:"name"`
+	Name    string `yaml:"name" schema:"required"`
 	Version string `yaml:"version"`
 
 	Organization string   `yaml:"organization,omitempty"`
@@ -342,6 +540,7 @@ type InfoImpl struct {
 	Contributors []string `yaml:"contributors,omitempty"`
 	Keywords     []string `yaml:"keywords,omitempty"`
 	IgnoreFiles  []string `yaml:"ignore_files,omitempty"`
+	Files        []string `yaml:"files,omitempty"`
 
 	Options     *OptionsImpl     `yaml:"compile_options"`
 	Definitions *DefinitionsImpl `yaml:"definitions,omitempty"`
@@ -391,6 +590,10 @@ func (i *InfoImpl) GetIgnoreFiles() []string {
 	return i.IgnoreFiles
 }
 
+func (i *InfoImpl) GetFiles() []string {
+	return i.Files
+}
+
 func (i *InfoImpl) GetOptions() Options {
 	return i.Options
 }
@@ -400,9 +603,12 @@ func (i *InfoImpl) GetDefinitions() Definitions {
 }
 
 type ConfigImpl struct {
-	Type         string                     `yaml:"type"`
-	Info         *InfoImpl                  `yaml:"project"`
+	Type         string                     `yaml:"type" schema:"required"`
+	Info         *InfoImpl                  `yaml:"project" schema:"required"`
 	Targets      map[string]*TargetImpl     `yaml:"targets"`
+	Templates    map[string]*TargetImpl     `yaml:"templates,omitempty"`
+	Variables    map[string]string          `yaml:"variables,omitempty"`
+	Profiles     map[string]*ProfileImpl    `yaml:"profiles,omitempty"`
 	Dependencies map[string]*DependencyImpl `yaml:"dependencies,omitempty"`
 	Libraries    map[string]*LibraryImpl    `yaml:"libraries,omitempty"`
 }
@@ -438,6 +644,14 @@ func (c *ConfigImpl) GetTargets() map[string]Target {
 	return s
 }
 
+func (c *ConfigImpl) GetProfiles() map[string]Profile {
+	s := map[string]Profile{}
+	for name, value := range c.Profiles {
+		s[name] = value
+	}
+	return s
+}
+
 func (c *ConfigImpl) GetDependencies() map[string]Dependency {
 	if c.Dependencies == nil {
 		c.Dependencies = map[string]*DependencyImpl{}
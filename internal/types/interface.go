@@ -12,14 +12,45 @@ type Properties interface {
 	GetPackage() []string
 }
 
+type Run interface {
+	GetArgs() []string
+	GetEnv() map[string]string
+	GetWorkingDir() string
+	GetStdin() string
+	GetSimulator() Simulator
+}
+
+type Simulator interface {
+	GetCommand() string
+	GetMcu() string
+	GetFrequency() uint64
+	GetTimeout() string
+	GetExitOn() string
+	GetFailOn() string
+}
+
 type Target interface {
 	GetSource() string
 	GetPlatform() string
 	GetFramework() string
 	GetBoard() string
+	GetBoards() []string
+	GetFrameworks() []string
+	GetMatrix() string
 	GetFlags() Properties
 	GetDefinitions() Properties
 	GetLinkerFlags() []string
+	GetRun() Run
+
+	GetName() string
+	SetName(name string)
+}
+
+type Profile interface {
+	GetBuildType() string
+	GetFlags() []string
+	GetDefinitions() []string
+	GetLinkerFlags() []string
 
 	GetName() string
 	SetName(name string)
@@ -51,6 +82,11 @@ type DependencyUrl interface {
 
 type Dependency interface {
 	GetUrl() DependencyUrl
+	GetPath() string
+	GetGit() string
+	GetRef() string
+	GetTag() string
+	GetCommit() string
 	IsVendor() bool
 	GetVersion() string
 	GetOsSupported() []string
@@ -96,6 +132,7 @@ type Info interface {
 	GetContributors() []string
 	GetKeywords() []string
 	GetIgnoreFiles() []string
+	GetFiles() []string
 
 	GetOptions() Options
 	GetDefinitions() Definitions
@@ -109,6 +146,7 @@ type Config interface {
 
 	GetInfo() Info
 	GetTargets() map[string]Target
+	GetProfiles() map[string]Profile
 	GetDependencies() map[string]Dependency
 	GetLibraries() map[string]Library
 
@@ -0,0 +1,337 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const (
+	variablesTag    = "variables"
+	dependenciesTag = "dependencies"
+
+	envPrefix        = "env:"
+	envDefaultSep    = ":-"
+	projectPrefix    = "project."
+	targetPrefix     = "target."
+	dependencyPrefix = "dependency."
+
+	// configs older than this wrote ${ for cmake and are only interpolated after migrating
+	expressionsVersion = "0.10.0"
+)
+
+// target values that are known once the target is resolved
+var targetFields = []string{"platform", "framework", "board"}
+
+type interpolator struct {
+	projectPath  string
+	legacy       bool
+	project      map[string]string
+	dependencies *yamlv3.Node
+}
+
+type interpolateScope struct {
+	variables  map[string]string
+	target     map[string]string
+	conditions map[string]string
+}
+
+// Replaces ${...} expressions in every value of wio.yml. Supported expressions are
+// ${env:NAME}, ${env:NAME:-default}, user variables from variables blocks, ${project.name},
+// ${project.version}, ${project.path}, ${target.name}, ${target.board}, ${target.platform},
+// ${target.framework} and ${dependency.<name>.path}. $${ escapes an expression. when conditions
+// are evaluated before values are interpolated, outside of targets they use the target being built.
+// Configs with a wio_version before 0.10.0 keep ${...} as is until they are migrated
+func interpolateConfig(document *yamlv3.Node, projectPath string, context map[string]string) error {
+	root := documentRoot(document)
+	if root == nil || root.Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	info := mappingValue(root, "project")
+	wioVersion := semver.Parse(scalarValue(mappingValue(mappingValue(info, "compile_options"), wioVersionTag)))
+	interp := &interpolator{
+		projectPath:  projectPath,
+		legacy:       wioVersion != nil && wioVersion.LT(*semver.Parse(expressionsVersion)),
+		dependencies: mappingValue(root, dependenciesTag),
+		project: map[string]string{
+			"name":    scalarValue(mappingValue(info, "name")),
+			"version": scalarValue(mappingValue(info, "version")),
+			"path":    filepath.ToSlash(projectPath),
+		},
+	}
+
+	scope := &interpolateScope{variables: map[string]string{}, conditions: context}
+	if err := interp.readVariables(mappingValue(root, variablesTag), scope); err != nil {
+		return err
+	}
+	deleteMappingKey(root, variablesTag)
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == targetsTag {
+			continue
+		}
+		if err := interp.interpolateNode(root.Content[i+1], scope); err != nil {
+			return err
+		}
+	}
+
+	targets := mappingValue(root, targetsTag)
+	if targets == nil || targets.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	content := make([]*yamlv3.Node, 0, len(targets.Content))
+	for i := 0; i+1 < len(targets.Content); i += 2 {
+		keep, err := interp.interpolateTarget(targets.Content[i].Value, targets.Content[i+1], scope)
+		if err != nil {
+			return err
+		} else if keep {
+			content = append(content, targets.Content[i], targets.Content[i+1])
+		}
+	}
+	targets.Content = content
+	return nil
+}
+
+func scalarValue(node *yamlv3.Node) string {
+	if node == nil || node.Kind != yamlv3.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// Interpolates a target and evaluates its conditions against its own platform, framework and board
+func (interp *interpolator) interpolateTarget(name string, target *yamlv3.Node, projectScope *interpolateScope) (bool, error) {
+	if target.Kind != yamlv3.MappingNode {
+		return true, nil
+	}
+
+	scope := &interpolateScope{variables: map[string]string{}, target: map[string]string{"name": name}}
+	for name, value := range projectScope.variables {
+		scope.variables[name] = value
+	}
+	if err := interp.readVariables(mappingValue(target, variablesTag), scope); err != nil {
+		return false, err
+	}
+	deleteMappingKey(target, variablesTag)
+
+	for _, field := range targetFields {
+		node := mappingValue(target, field)
+		if err := interp.interpolateNode(node, scope); err != nil {
+			return false, err
+		}
+		scope.target[field] = scalarValue(node)
+	}
+	scope.conditions = scope.target
+
+	if keep, err := interp.resolveCondition(target, scope); err != nil || !keep {
+		return false, err
+	}
+	return true, interp.interpolateNode(target, scope)
+}
+
+// Reads a variables block, variables can reference variables defined before them
+func (interp *interpolator) readVariables(variables *yamlv3.Node, scope *interpolateScope) error {
+	if variables == nil {
+		return nil
+	}
+	if variables.Kind != yamlv3.MappingNode {
+		return nodeError(variables, "variables must be a map of names to values")
+	}
+
+	for i := 0; i+1 < len(variables.Content); i += 2 {
+		name, value := variables.Content[i], variables.Content[i+1]
+		if value.Kind != yamlv3.ScalarNode {
+			return nodeError(value, "variable %s must have a single value", name.Value)
+		}
+		if err := interp.interpolateNode(value, scope); err != nil {
+			return err
+		}
+		scope.variables[name.Value] = value.Value
+	}
+	return nil
+}
+
+func (interp *interpolator) interpolateNode(node *yamlv3.Node, scope *interpolateScope) error {
+	if node == nil {
+		return nil
+	}
+
+	switch node.Kind {
+	case yamlv3.ScalarNode:
+		if !strings.Contains(node.Value, "$") {
+			return nil
+		}
+		value, err := interp.interpolate(node.Value, scope)
+		if err != nil {
+			return nodeError(node, "%s", err.Error())
+		}
+		node.Value = value
+		// let plain scalars be decoded as numbers and booleans after interpolation
+		if node.Style == 0 {
+			node.Tag = ""
+		}
+	case yamlv3.MappingNode:
+		if err := interp.resolveConditions(node, scope); err != nil {
+			return err
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := interp.interpolateNode(node.Content[i], scope); err != nil {
+				return err
+			}
+		}
+	case yamlv3.SequenceNode:
+		if err := interp.resolveConditions(node, scope); err != nil {
+			return err
+		}
+		for _, child := range node.Content {
+			if err := interp.interpolateNode(child, scope); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (interp *interpolator) interpolate(value string, scope *interpolateScope) (string, error) {
+	// older wio.yml files use $(PROJECT_PATH)
+	value = strings.Replace(value, "$(PROJECT_PATH)", interp.projectPath, -1)
+	if interp.legacy {
+		return value, nil
+	}
+
+	result := strings.Builder{}
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			result.WriteString(value)
+			return result.String(), nil
+		}
+
+		// $${ is an escaped expression
+		if start > 0 && value[start-1] == '$' {
+			result.WriteString(value[:start-1] + "${")
+			value = value[start+2:]
+			continue
+		}
+
+		end := strings.Index(value[start:], "}")
+		if end < 0 {
+			return "", util.Error("unterminated expression in %s", value)
+		}
+		end += start
+
+		resolved, err := interp.evaluate(strings.TrimSpace(value[start+2:end]), scope)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(value[:start] + resolved)
+		value = value[end+1:]
+	}
+}
+
+func (interp *interpolator) evaluate(expression string, scope *interpolateScope) (string, error) {
+	switch {
+	case strings.HasPrefix(expression, envPrefix):
+		name := strings.TrimPrefix(expression, envPrefix)
+		defaultValue, hasDefault := "", false
+		if index := strings.Index(name, envDefaultSep); index >= 0 {
+			defaultValue, hasDefault = name[index+len(envDefaultSep):], true
+			name = name[:index]
+		}
+		if value, exists := os.LookupEnv(name); exists && (value != "" || !hasDefault) {
+			return value, nil
+		} else if hasDefault {
+			return defaultValue, nil
+		}
+		return "", util.Error("environment variable %s is not set", name)
+
+	case strings.HasPrefix(expression, projectPrefix):
+		field := strings.TrimPrefix(expression, projectPrefix)
+		if value, exists := interp.project[field]; exists {
+			return value, nil
+		}
+		return "", util.Error("unknown project value %s", expression)
+
+	case strings.HasPrefix(expression, targetPrefix):
+		field := strings.TrimPrefix(expression, targetPrefix)
+		if scope.target == nil {
+			return "", util.Error("%s can only be used inside of a target", expression)
+		}
+		if value, exists := scope.target[field]; exists {
+			return value, nil
+		}
+		return "", util.Error("unknown target value %s", expression)
+
+	case strings.HasPrefix(expression, dependencyPrefix) && strings.HasSuffix(expression, ".path"):
+		name := strings.TrimSuffix(strings.TrimPrefix(expression, dependencyPrefix), ".path")
+		return interp.dependencyPath(name)
+
+	default:
+		if value, exists := scope.variables[expression]; exists {
+			return value, nil
+		}
+		return "", util.Error("undefined variable %s", expression)
+	}
+}
+
+// Finds where a dependency is on disk, vendored or installed. Dependencies that are not installed
+// yet resolve to the path they will be installed at
+func (interp *interpolator) dependencyPath(name string) (string, error) {
+	dependency := mappingValue(interp.dependencies, name)
+	if dependency == nil {
+		return "", util.Error("%s is not a dependency", name)
+	}
+
+	if path := scalarValue(mappingValue(dependency, "path")); path != "" {
+		if !filepath.IsAbs(path) {
+			path = sys.Path(interp.projectPath, path)
+		}
+		return filepath.ToSlash(filepath.Clean(path)), nil
+	}
+	if scalarValue(mappingValue(dependency, "git")) != "" {
+		return filepath.ToSlash(sys.Path(interp.projectPath, sys.WioFolder, sys.Modules, sys.Git, name)), nil
+	}
+
+	vendorPath := sys.Path(interp.projectPath, sys.Vendor, name)
+	if sys.Exists(vendorPath) {
+		return filepath.ToSlash(vendorPath), nil
+	}
+
+	// installed folders are name__version, the newest version matching the declared one is used
+	version := scalarValue(mappingValue(dependency, "version"))
+	query := semver.MakeQuery(version)
+	if version == "" {
+		query = semver.MakeQuery("*")
+	}
+	patterns := []string{
+		sys.Path(interp.projectPath, sys.Vendor, name+"__*"),
+		sys.Path(interp.projectPath, sys.WioFolder, sys.Modules, name+"__*"),
+		sys.Path(interp.projectPath, sys.WioFolder, sys.Modules, sys.Custom, name+"__*"),
+	}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return "", err
+		}
+		paths := map[string]string{}
+		var versions semver.List
+		for _, match := range matches {
+			installed := semver.Parse(strings.TrimPrefix(filepath.Base(match), name+"__"))
+			if installed != nil && query != nil && query.Matches(installed) {
+				paths[installed.String()] = match
+				versions = append(versions, installed)
+			}
+		}
+		if len(versions) > 0 {
+			versions.Sort()
+			return filepath.ToSlash(paths[versions.Last().String()]), nil
+		}
+	}
+	return filepath.ToSlash(sys.Path(interp.projectPath, sys.WioFolder, sys.Modules, name+"__"+version)), nil
+}
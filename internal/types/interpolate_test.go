@@ -0,0 +1,131 @@
+package types
+
+import (
+	"os"
+	"testing"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolateConfig(t *testing.T) {
+	os.Setenv("WIO_TEST_BOARD", "uno")
+	defer os.Unsetenv("WIO_TEST_BOARD")
+
+	dir := writeTestConfig(t, `type: app
+project:
+  name: demo
+  version: 0.1.0
+  compile_options:
+    wio_version: 0.10.0
+variables:
+  lib: ${project.name}-lib
+targets:
+  main:
+    src: src
+    platform: avr
+    board: ${env:WIO_TEST_BOARD}
+    definitions:
+      target:
+      - NAME=${target.name}
+      - BOARD=${target.board}
+      - LIB=${lib}
+      - CMAKE=$${CMAKE_VAR}
+libraries:
+  foo:
+    path: ${project.path}/lib
+`)
+	defer os.RemoveAll(dir)
+
+	config, err := ReadWioConfig(dir, true)
+	assert.NoError(t, err)
+	target := config.GetTargets()["main"]
+	assert.Equal(t, "uno", target.GetBoard())
+	assert.Equal(t, []string{"NAME=main", "BOARD=uno", "LIB=demo-lib", "CMAKE=${CMAKE_VAR}"},
+		target.GetDefinitions().GetTarget())
+	assert.Equal(t, dir+"/lib", config.GetLibraries()["foo"].GetPath())
+}
+
+func TestInterpolateUndefinedVariable(t *testing.T) {
+	dir := writeTestConfig(t, `type: app
+project:
+  name: demo
+  version: 0.1.0
+  compile_options:
+    wio_version: 0.10.0
+libraries:
+  foo:
+    path: ${LIB_PATH}/foo
+`)
+	defer os.RemoveAll(dir)
+
+	_, err := ReadWioConfig(dir, true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "undefined variable LIB_PATH")
+}
+
+func TestInterpolateLegacyConfig(t *testing.T) {
+	dir := writeTestConfig(t, `type: app
+project:
+  name: demo
+  version: 0.1.0
+  compile_options:
+    wio_version: 0.9.0
+targets:
+  main:
+    src: src
+    platform: native
+libraries:
+  foo:
+    path: ${LIB_PATH}/foo
+    include_path:
+    - $(PROJECT_PATH)/include
+`)
+	defer os.RemoveAll(dir)
+
+	config, err := ReadWioConfig(dir, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "${LIB_PATH}/foo", config.GetLibraries()["foo"].GetPath())
+	assert.Equal(t, []string{dir + "/include"}, config.GetLibraries()["foo"].GetIncludePath())
+	assert.True(t, NeedsMigration(config))
+}
+
+func TestInterpolateDependencyPath(t *testing.T) {
+	dir := writeTestConfig(t, `type: app
+project:
+  name: demo
+  version: 0.1.0
+  compile_options:
+    wio_version: 0.10.0
+targets:
+  main:
+    src: src
+    platform: native
+    definitions:
+      target:
+      - FOO=${dependency.foo.path}
+      - BAR=${dependency.bar.path}
+      - BAZ=${dependency.baz.path}
+dependencies:
+  foo:
+    version: ^1.0.0
+  bar:
+    version: ~1.2.0
+  baz:
+    version: 3.0.0
+`)
+	defer os.RemoveAll(dir)
+
+	modules := sys.Path(dir, sys.WioFolder, sys.Modules)
+	for _, folder := range []string{"foo__1.9.0", "foo__1.10.0", "foo__2.0.0", "bar__1.2.3", "bar__1.3.0"} {
+		assert.NoError(t, os.MkdirAll(sys.Path(modules, folder), os.ModePerm))
+	}
+
+	config, err := ReadWioConfig(dir, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"FOO=" + modules + "/foo__1.10.0",
+		"BAR=" + modules + "/bar__1.2.3",
+		"BAZ=" + modules + "/baz__3.0.0",
+	}, config.GetTargets()["main"].GetDefinitions().GetTarget())
+}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+)
+
+// wio.lock records what dependencies resolved to so that every install of a project uses the same
+// code until a dependency is updated on purpose
+type LockImpl struct {
+	Git map[string]*GitLockImpl `yaml:"git,omitempty"`
+	// versions toolchains from the registry resolved to by name@range and commits toolchains from
+	// git resolved to by url@ref
+	Toolchains map[string]string `yaml:"toolchains,omitempty"`
+	// integrity of toolchain tarballs by name@version
+	Integrity map[string]string `yaml:"integrity,omitempty"`
+
+	changed bool
+}
+
+// Commit a git dependency resolved to along with what was asked for
+type GitLockImpl struct {
+	Url    string `yaml:"url"`
+	Ref    string `yaml:"ref,omitempty"`
+	Tag    string `yaml:"tag,omitempty"`
+	Commit string `yaml:"commit"`
+}
+
+// Returns the commit locked for a git dependency as long as it is still asked for the same way
+func (l *LockImpl) GitCommit(name string, dep Dependency) string {
+	if locked, exists := l.Git[name]; exists && locked.Url == dep.GetGit() &&
+		locked.Ref == dep.GetRef() && locked.Tag == dep.GetTag() {
+		return locked.Commit
+	}
+	return ""
+}
+
+// Records the commit a git dependency resolved to
+func (l *LockImpl) SetGitCommit(name string, dep Dependency, commit string) {
+	if l.Git == nil {
+		l.Git = map[string]*GitLockImpl{}
+	}
+	l.Git[name] = &GitLockImpl{Url: dep.GetGit(), Ref: dep.GetRef(), Tag: dep.GetTag(), Commit: commit}
+	l.changed = true
+}
+
+// Returns the version locked for a toolchain range
+func (l *LockImpl) ToolchainVersion(name string, query string) string {
+	return l.Toolchains[name+"@"+query]
+}
+
+// Records the version a toolchain range resolved to
+func (l *LockImpl) SetToolchainVersion(name string, query string, version string) {
+	if l.Toolchains == nil {
+		l.Toolchains = map[string]string{}
+	}
+	if l.Toolchains[name+"@"+query] != version {
+		l.Toolchains[name+"@"+query] = version
+		l.changed = true
+	}
+}
+
+// Returns the integrity recorded for a toolchain tarball
+func (l *LockImpl) ToolchainIntegrity(name string, version string) string {
+	return l.Integrity[name+"@"+version]
+}
+
+// Records the integrity of a toolchain tarball
+func (l *LockImpl) SetToolchainIntegrity(name string, version string, integrity string) {
+	if l.Integrity == nil {
+		l.Integrity = map[string]string{}
+	}
+	if l.Integrity[name+"@"+version] != integrity {
+		l.Integrity[name+"@"+version] = integrity
+		l.changed = true
+	}
+}
+
+// Whether the lock was changed since it was read
+func (l *LockImpl) Changed() bool {
+	return l.changed
+}
+
+// Reads wio.lock of a project. A project without one gets an empty lock
+func ReadLock(dir string) (*LockImpl, error) {
+	lock := &LockImpl{}
+	path := sys.Path(dir, sys.Lock)
+	if !sys.Exists(path) {
+		return lock, nil
+	}
+	if err := sys.NormalIO.ParseYml(path, lock); err != nil {
+		return nil, util.Error("%s: %s", path, err.Error())
+	}
+	return lock, nil
+}
+
+func WriteLock(dir string, lock *LockImpl) error {
+	return sys.NormalIO.WriteYml(sys.Path(dir, sys.Lock), lock)
+}
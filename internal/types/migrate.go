@@ -0,0 +1,265 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const wioVersionTag = "wio_version"
+
+// Migration rewrites wio.yml from the schema before version to the schema of version
+type Migration struct {
+	Version     string
+	Description string
+	migrate     func(root *yamlv3.Node) error
+}
+
+// migrations in the order they are applied
+var migrations = []Migration{
+	{
+		Version:     "0.10.0",
+		Description: "escape ${ and use ${project.path} instead of $(PROJECT_PATH)",
+		migrate:     migrateProjectPath,
+	},
+	{
+		Version:     "0.10.1",
+		Description: "use when conditions instead of $linux(...), $darwin(...) and $windows(...) in lists",
+		migrate:     migrateLegacySelectors,
+	},
+	{
+		Version:     "0.10.2",
+		Description: "write yes, no, on and off as true and false, they are only read as booleans in boolean fields",
+		migrate:     migrateBooleans,
+	},
+}
+
+// YAML 1.1 booleans that YAML 1.2 reads as strings
+var legacyBooleans = map[string]string{
+	"y": "true", "yes": "true", "on": "true",
+	"n": "false", "no": "false", "off": "false",
+}
+
+// Returns migrations that have to be applied to wio.yml with the provided wio_version
+func PendingMigrations(wioVersion string) ([]Migration, error) {
+	version := semver.Parse(wioVersion)
+	if version == nil {
+		return nil, util.Error("wio.yml does not have a valid %s: %s", wioVersionTag, wioVersion)
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if version.LT(*semver.Parse(migration.Version)) {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Checks if wio.yml of a config has to be migrated to the latest schema
+func NeedsMigration(config Config) bool {
+	pending, err := PendingMigrations(config.GetInfo().GetOptions().GetWioVersion())
+	return err == nil && len(pending) > 0
+}
+
+// Migrates wio.yml of a project to the latest schema. Old and migrated text are returned along
+// with migrations that were applied. Only entries that migrations change are rewritten, so
+// comments, blank lines and order of keys are kept
+func MigrateWioConfig(dir string) ([]byte, []byte, []Migration, error) {
+	editor, err := EditWioConfig(dir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	text := editor.Text()
+
+	migrated := copyNode(editor.document)
+	version := FindNode(migrated, "project", "compile_options", wioVersionTag)
+	if version == nil || version.Kind != yamlv3.ScalarNode {
+		return nil, nil, nil, util.Error("wio.yml is missing project.compile_options.%s", wioVersionTag)
+	}
+	pending, err := PendingMigrations(version.Value)
+	if err != nil || len(pending) <= 0 {
+		return text, text, nil, err
+	}
+
+	root := documentRoot(migrated)
+	for _, migration := range pending {
+		if err := migration.migrate(root); err != nil {
+			return nil, nil, nil, util.Error("migrating to %s: %s", migration.Version, err.Error())
+		}
+		version.Value = migration.Version
+	}
+
+	for _, keys := range changedEntries(documentRoot(editor.document), root, nil) {
+		if len(keys) <= 0 {
+			return nil, nil, nil, util.Error("%s must be a map", sys.Config)
+		}
+		if err := editor.SetNode(FindNode(migrated, keys...), keys...); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return text, editor.Text(), pending, nil
+}
+
+// Finds the entries of a migrated node that are different from the original by the keys leading
+// to them. Lists and values are compared as a whole
+func changedEntries(original *yamlv3.Node, migrated *yamlv3.Node, keys []string) [][]string {
+	if original.Kind == yamlv3.MappingNode && migrated.Kind == yamlv3.MappingNode &&
+		len(original.Content) == len(migrated.Content) {
+		var changed [][]string
+		for i := 0; i+1 < len(original.Content); i += 2 {
+			if original.Content[i].Value != migrated.Content[i].Value {
+				return [][]string{keys}
+			}
+			entryKeys := append(append([]string{}, keys...), original.Content[i].Value)
+			changed = append(changed, changedEntries(original.Content[i+1], migrated.Content[i+1], entryKeys)...)
+		}
+		return changed
+	}
+	if equalNodes(original, migrated) {
+		return nil
+	}
+	return [][]string{keys}
+}
+
+func equalNodes(a *yamlv3.Node, b *yamlv3.Node) bool {
+	if a.Kind != b.Kind || a.Tag != b.Tag || a.Value != b.Value || a.Style != b.Style ||
+		len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !equalNodes(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Writes migrated wio.yml of a project
+func WriteWioText(dir string, text []byte) error {
+	return sys.NormalIO.WriteFile(sys.Path(dir, sys.Config), text)
+}
+
+// calls visit for every scalar inside of node
+func walkScalars(node *yamlv3.Node, visit func(scalar *yamlv3.Node)) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yamlv3.ScalarNode {
+		visit(node)
+		return
+	}
+	for i, child := range node.Content {
+		// keys are never interpolated
+		if node.Kind == yamlv3.MappingNode && i%2 == 0 {
+			continue
+		}
+		walkScalars(child, visit)
+	}
+}
+
+// ${ was written as is before expressions were supported so it is escaped. $(PROJECT_PATH)
+// becomes ${project.path}
+func migrateProjectPath(root *yamlv3.Node) error {
+	walkScalars(root, func(scalar *yamlv3.Node) {
+		value := strings.Replace(scalar.Value, "${", "$${", -1)
+		scalar.Value = strings.Replace(value, "$(PROJECT_PATH)", "${project.path}", -1)
+	})
+	return nil
+}
+
+// Items of a list using $linux(...), $darwin(...) and $windows(...) become values with when conditions
+func migrateLegacySelectors(root *yamlv3.Node) error {
+	var migrate func(node *yamlv3.Node)
+	migrate = func(node *yamlv3.Node) {
+		if node.Kind == yamlv3.SequenceNode {
+			content := make([]*yamlv3.Node, 0, len(node.Content))
+			for _, item := range node.Content {
+				if item.Kind != yamlv3.ScalarNode || !legacyOSSelectors.MatchString(item.Value) {
+					content = append(content, item)
+					continue
+				}
+				for i, match := range legacyOSSelector.FindAllStringSubmatch(item.Value, -1) {
+					conditional := conditionalNode(match[1], strings.TrimSpace(match[2]))
+					if i == 0 {
+						conditional.HeadComment, conditional.LineComment = item.HeadComment, item.LineComment
+					}
+					content = append(content, conditional)
+				}
+			}
+			node.Content = content
+		}
+		for _, child := range node.Content {
+			migrate(child)
+		}
+	}
+	migrate(root)
+	return nil
+}
+
+// Boolean fields written as yes, no, on or off are rewritten as true and false
+func migrateBooleans(root *yamlv3.Node) error {
+	var migrate func(node *yamlv3.Node, t reflect.Type)
+	migrate = func(node *yamlv3.Node, t reflect.Type) {
+		if value, _ := conditionalValue(node); value != nil {
+			node = value
+		}
+		switch t.Kind() {
+		case reflect.Ptr:
+			migrate(node, t.Elem())
+		case reflect.Struct:
+			fields := map[string]yamlField{}
+			for _, field := range yamlFields(t) {
+				fields[field.name] = field
+			}
+			for i := 0; node.Kind == yamlv3.MappingNode && i+1 < len(node.Content); i += 2 {
+				if field, exists := fields[node.Content[i].Value]; exists {
+					migrate(node.Content[i+1], t.Field(field.index).Type)
+				}
+			}
+		case reflect.Map:
+			for i := 1; node.Kind == yamlv3.MappingNode && i < len(node.Content); i += 2 {
+				migrate(node.Content[i], t.Elem())
+			}
+		case reflect.Slice:
+			for i := 0; node.Kind == yamlv3.SequenceNode && i < len(node.Content); i++ {
+				if value, splice := conditionalValue(node.Content[i]); value != nil && splice {
+					migrate(value, t)
+				} else {
+					migrate(node.Content[i], t.Elem())
+				}
+			}
+		case reflect.Bool:
+			if boolean, exists := legacyBooleans[strings.ToLower(node.Value)]; exists &&
+				node.Kind == yamlv3.ScalarNode && node.Style == 0 {
+				node.Value, node.Tag = boolean, "!!bool"
+			}
+		}
+	}
+	migrate(root, reflect.TypeOf(ConfigImpl{}))
+	return nil
+}
+
+func conditionalNode(osName string, value string) *yamlv3.Node {
+	scalar := func(value string) *yamlv3.Node {
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: value}
+	}
+	valueNode := scalar(value)
+	valueNode.Tag = ""
+
+	return &yamlv3.Node{
+		Kind:  yamlv3.MappingNode,
+		Tag:   "!!map",
+		Style: yamlv3.FlowStyle,
+		Content: []*yamlv3.Node{
+			scalar(whenTag),
+			{Kind: yamlv3.MappingNode, Tag: "!!map", Style: yamlv3.FlowStyle, Content: []*yamlv3.Node{scalar("os"), scalar(osName)}},
+			scalar(valueTag),
+			valueNode,
+		},
+	}
+}
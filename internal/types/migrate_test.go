@@ -0,0 +1,187 @@
+package types
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateBooleans(t *testing.T) {
+	dir := writeTestConfig(t, `type: pkg
+project:
+  name: pkg
+  compile_options:
+    wio_version: 0.10.1
+    header_only: yes # no sources
+dependencies:
+  fmt:
+    version: ^1.0.0
+    vendor: {when: {os: linux}, value: Off}
+libraries:
+  zlib:
+    cmake_package: "on"
+    variables:
+      ZLIB_STATIC: yes
+`)
+	defer os.RemoveAll(dir)
+
+	_, migrated, applied, err := MigrateWioConfig(dir)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, `type: pkg
+project:
+  name: pkg
+  compile_options:
+    wio_version: 0.10.2
+    header_only: true # no sources
+dependencies:
+  fmt:
+    version: ^1.0.0
+    vendor: {when: {os: linux}, value: false}
+libraries:
+  zlib:
+    cmake_package: "on"
+    variables:
+      ZLIB_STATIC: yes
+`, string(migrated))
+}
+
+func TestPendingMigrations(t *testing.T) {
+	pending, err := PendingMigrations("0.9.0")
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrations), len(pending))
+
+	// migrations are applied one schema version after the other
+	pending, err = PendingMigrations("0.10.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0.10.1", "0.10.2"}, []string{pending[0].Version, pending[1].Version})
+
+	pending, err = PendingMigrations("0.10.2")
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	_, err = PendingMigrations("latest")
+	assert.EqualError(t, err, "wio.yml does not have a valid wio_version: latest")
+}
+
+func TestMigrateProjectPath(t *testing.T) {
+	dir := writeTestConfig(t, `# application
+type: app
+
+project:
+  name:   app   # aligned
+  compile_options:
+    wio_version: 0.9.0
+    flags:
+    - -I$(PROJECT_PATH)/include
+    - -DHOME=${HOME} # cmake variable
+
+targets:
+    main:
+        src: src
+        platform: native
+libraries:
+  foo:
+    path: ${LIB_PATH}/foo
+    variables:
+      ${KEY}: ${VALUE}
+`)
+	defer os.RemoveAll(dir)
+
+	text, migrated, applied, err := MigrateWioConfig(dir)
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(migrations))
+	assert.Equal(t, `# application
+type: app
+
+project:
+  name:   app   # aligned
+  compile_options:
+    wio_version: 0.10.2
+    flags:
+    - -I${project.path}/include
+    - -DHOME=$${HOME} # cmake variable
+
+targets:
+    main:
+        src: src
+        platform: native
+libraries:
+  foo:
+    path: $${LIB_PATH}/foo
+    variables:
+      ${KEY}: $${VALUE}
+`, string(migrated))
+
+	// migrated config resolves to what the legacy config did
+	legacy, err := ReadWioConfig(dir, true)
+	assert.NoError(t, err)
+	assert.NoError(t, WriteWioText(dir, migrated))
+	current, err := ReadWioConfig(dir, true)
+	assert.NoError(t, err)
+	assert.Equal(t, legacy.GetInfo().GetOptions().GetFlags(), current.GetInfo().GetOptions().GetFlags())
+	assert.Equal(t, legacy.GetLibraries()["foo"].GetPath(), current.GetLibraries()["foo"].GetPath())
+	assert.Equal(t, legacy.GetLibraries()["foo"].GetVariables(), current.GetLibraries()["foo"].GetVariables())
+
+	// migrated configs are left as they are
+	unchanged, migratedAgain, applied, err := MigrateWioConfig(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Equal(t, migrated, unchanged)
+	assert.Equal(t, migrated, migratedAgain)
+	assert.NotEqual(t, text, migrated)
+}
+
+func TestMigrateLegacySelectors(t *testing.T) {
+	dir := writeTestConfig(t, `type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.9.0
+targets:
+  main:
+    src: src
+    platform: native
+    linker_flags:
+    - -lm
+    # threads
+    - $linux(-pthread) $darwin(-lpthread)
+    - $windows(-lws2_32)
+`)
+	defer os.RemoveAll(dir)
+
+	_, migrated, _, err := MigrateWioConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, `type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.10.2
+targets:
+  main:
+    src: src
+    platform: native
+    linker_flags:
+    - -lm
+    # threads
+    - {when: {os: linux}, value: -pthread}
+    - {when: {os: darwin}, value: -lpthread}
+    - {when: {os: windows}, value: -lws2_32}
+`, string(migrated))
+
+	legacy, err := ReadWioConfig(dir, true)
+	assert.NoError(t, err)
+	assert.NoError(t, WriteWioText(dir, migrated))
+	current, err := ReadWioConfig(dir, true)
+	assert.NoError(t, err)
+	assert.Equal(t, legacy.GetTargets()["main"].GetLinkerFlags(), current.GetTargets()["main"].GetLinkerFlags())
+}
+
+func TestMigrateMissingVersion(t *testing.T) {
+	dir := writeTestConfig(t, "type: app\nproject:\n  name: app\n")
+	defer os.RemoveAll(dir)
+
+	_, _, _, err := MigrateWioConfig(dir)
+	assert.EqualError(t, err, "wio.yml is missing project.compile_options.wio_version")
+}
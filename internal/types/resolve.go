@@ -0,0 +1,317 @@
+package types
+
+import (
+	"wio/pkg/util"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const (
+	targetsTag    = "targets"
+	templatesTag  = "templates"
+	extendsTag    = "extends"
+	boardsTag     = "boards"
+	frameworksTag = "frameworks"
+	matrixTag     = "matrix"
+
+	// lists and maps tagged with !override replace what they inherit instead of merging with it
+	overrideTag = "!override"
+)
+
+// Resolves wio.yml document before it gets decoded. Targets are merged with the targets and
+// templates they extend, expanded into build matrices and then conditions and variables are
+// resolved. Context holds platform, framework and board of the target being built if any
+func resolveConfig(document *yamlv3.Node, projectPath string, context map[string]string) error {
+	root := documentRoot(document)
+	if root == nil || root.Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	if err := resolveTargets(root); err != nil {
+		return err
+	}
+	if err := expandMatrices(mappingValue(root, targetsTag)); err != nil {
+		return err
+	}
+	return interpolateConfig(document, projectPath, context)
+}
+
+// Returns the top level node of a document
+func documentRoot(document *yamlv3.Node) *yamlv3.Node {
+	if document.Kind == yamlv3.DocumentNode {
+		if len(document.Content) <= 0 {
+			return nil
+		}
+		return document.Content[0]
+	}
+	return document
+}
+
+// Finds value of a key inside of a mapping node
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Removes a key from a mapping node
+func deleteMappingKey(node *yamlv3.Node, key string) {
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+type extendsResolver struct {
+	targets   *yamlv3.Node
+	templates *yamlv3.Node
+	resolved  map[*yamlv3.Node]bool
+	visiting  map[*yamlv3.Node]bool
+}
+
+// Merges every target with the target or template it extends. Templates are removed
+// once all the targets are resolved
+func resolveTargets(root *yamlv3.Node) error {
+	resolver := &extendsResolver{
+		targets:   mappingValue(root, targetsTag),
+		templates: mappingValue(root, templatesTag),
+		resolved:  map[*yamlv3.Node]bool{},
+		visiting:  map[*yamlv3.Node]bool{},
+	}
+
+	if resolver.targets != nil && resolver.targets.Kind == yamlv3.MappingNode {
+		for i := 0; i+1 < len(resolver.targets.Content); i += 2 {
+			name := resolver.targets.Content[i].Value
+			merged, err := resolver.resolve(name, resolver.targets.Content[i+1])
+			if err != nil {
+				return err
+			}
+			resolver.targets.Content[i+1] = merged
+		}
+	}
+
+	deleteMappingKey(root, templatesTag)
+	clearOverrides(root)
+	return nil
+}
+
+func (resolver *extendsResolver) lookup(name string) (*yamlv3.Node, error) {
+	target := mappingValue(resolver.targets, name)
+	template := mappingValue(resolver.templates, name)
+
+	if target != nil && template != nil {
+		return nil, util.Error("%s is both a target and a template", name)
+	} else if target != nil {
+		return target, nil
+	} else if template != nil {
+		return template, nil
+	}
+	return nil, util.Error("no target or template named %s", name)
+}
+
+func (resolver *extendsResolver) resolve(name string, node *yamlv3.Node) (*yamlv3.Node, error) {
+	if node.Kind != yamlv3.MappingNode || resolver.resolved[node] {
+		return node, nil
+	}
+
+	if resolver.visiting[node] {
+		return nil, nodeError(node, "%s extends itself", name)
+	}
+	resolver.visiting[node] = true
+	defer delete(resolver.visiting, node)
+
+	extends := mappingValue(node, extendsTag)
+	if extends == nil {
+		resolver.resolved[node] = true
+		return node, nil
+	}
+	if extends.Kind != yamlv3.ScalarNode {
+		return nil, nodeError(extends, "%s must extend a single target or template", name)
+	}
+
+	parent, err := resolver.lookup(extends.Value)
+	if err != nil {
+		return nil, nodeError(extends, "%s", err.Error())
+	}
+	parent, err = resolver.resolve(extends.Value, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	deleteMappingKey(node, extendsTag)
+	*node = *mergeNodes(parent, node)
+	resolver.resolved[node] = true
+	return node, nil
+}
+
+// Expands targets with boards or frameworks into one target per combination. Each expanded
+// target is named <target>__<board>__<framework> and remembers the target it came from
+func expandMatrices(targets *yamlv3.Node) error {
+	if targets == nil || targets.Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	content := make([]*yamlv3.Node, 0, len(targets.Content))
+	for i := 0; i+1 < len(targets.Content); i += 2 {
+		key, target := targets.Content[i], targets.Content[i+1]
+		boards, err := matrixValues(target, boardsTag)
+		if err != nil {
+			return err
+		}
+		frameworks, err := matrixValues(target, frameworksTag)
+		if err != nil {
+			return err
+		}
+		if boards == nil && frameworks == nil {
+			content = append(content, key, target)
+			continue
+		}
+
+		deleteMappingKey(target, boardsTag)
+		deleteMappingKey(target, frameworksTag)
+		if boards == nil {
+			boards = []*yamlv3.Node{nil}
+		}
+		if frameworks == nil {
+			frameworks = []*yamlv3.Node{nil}
+		}
+
+		for _, board := range boards {
+			for _, framework := range frameworks {
+				name := key.Value
+				expanded := copyNode(target)
+				if board != nil {
+					name += "__" + board.Value
+					setMappingValue(expanded, "board", copyNode(board))
+				}
+				if framework != nil {
+					name += "__" + framework.Value
+					setMappingValue(expanded, "framework", copyNode(framework))
+				}
+				setMappingValue(expanded, matrixTag, &yamlv3.Node{
+					Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key.Value, Line: key.Line, Column: key.Column,
+				})
+
+				expandedKey := copyNode(key)
+				expandedKey.Value = name
+				content = append(content, expandedKey, expanded)
+			}
+		}
+	}
+	targets.Content = content
+	return nil
+}
+
+func matrixValues(target *yamlv3.Node, tag string) ([]*yamlv3.Node, error) {
+	values := mappingValue(target, tag)
+	if values == nil {
+		return nil, nil
+	}
+	if values.Kind != yamlv3.SequenceNode || len(values.Content) <= 0 {
+		return nil, nodeError(values, "%s must be a list of values", tag)
+	}
+	for _, value := range values.Content {
+		if value.Kind != yamlv3.ScalarNode {
+			return nil, nodeError(value, "%s must be a list of values", tag)
+		}
+	}
+	return values.Content, nil
+}
+
+// Sets value of a key inside of a mapping node
+func setMappingValue(node *yamlv3.Node, key string, value *yamlv3.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content,
+		&yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key, Line: value.Line, Column: value.Column},
+		value)
+}
+
+// Merges override on top of base. Mappings are merged key by key, sequences are appended
+// without duplicates and anything else is replaced by the override. Nodes tagged with !override
+// are never merged and replace the base as a whole
+func mergeNodes(base *yamlv3.Node, override *yamlv3.Node) *yamlv3.Node {
+	if base.Kind != override.Kind || override.Tag == overrideTag {
+		return copyNode(override)
+	}
+
+	switch override.Kind {
+	case yamlv3.MappingNode:
+		merged := copyNode(base)
+		for i := 0; i+1 < len(override.Content); i += 2 {
+			key, value := override.Content[i], override.Content[i+1]
+			replaced := false
+			for j := 0; j+1 < len(merged.Content); j += 2 {
+				if merged.Content[j].Value == key.Value {
+					merged.Content[j+1] = mergeNodes(merged.Content[j+1], value)
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				merged.Content = append(merged.Content, copyNode(key), copyNode(value))
+			}
+		}
+		return merged
+	case yamlv3.SequenceNode:
+		merged := copyNode(base)
+		for _, item := range override.Content {
+			if !containsScalar(merged, item) {
+				merged.Content = append(merged.Content, copyNode(item))
+			}
+		}
+		return merged
+	default:
+		return copyNode(override)
+	}
+}
+
+func containsScalar(sequence *yamlv3.Node, item *yamlv3.Node) bool {
+	if item.Kind != yamlv3.ScalarNode {
+		return false
+	}
+	for _, value := range sequence.Content {
+		if value.Kind == yamlv3.ScalarNode && value.Value == item.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// Removes !override tags once targets are merged so that nodes decode as plain lists and maps
+func clearOverrides(node *yamlv3.Node) {
+	if node.Tag == overrideTag {
+		node.Tag = ""
+	}
+	for _, child := range node.Content {
+		clearOverrides(child)
+	}
+}
+
+// Deep copies a node so that merged targets never share nodes
+func copyNode(node *yamlv3.Node) *yamlv3.Node {
+	if node == nil {
+		return nil
+	}
+	copied := *node
+	copied.Content = make([]*yamlv3.Node, 0, len(node.Content))
+	for _, child := range node.Content {
+		copied.Content = append(copied.Content, copyNode(child))
+	}
+	return &copied
+}
@@ -0,0 +1,336 @@
+package types
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Writes wio.yml to a temporary project directory
+func writeTestConfig(t *testing.T, text string) string {
+	dir, err := ioutil.TempDir("", "wio-config")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(sys.Path(dir, sys.Config), []byte(text), 0644))
+	return dir
+}
+
+func resolveTargetsText(t *testing.T, text string) (string, error) {
+	document := &yamlv3.Node{}
+	assert.NoError(t, yamlv3.Unmarshal([]byte(text), document))
+	if err := resolveTargets(documentRoot(document)); err != nil {
+		return "", err
+	}
+	encoded, err := EncodeNode(document)
+	assert.NoError(t, err)
+	return string(encoded), nil
+}
+
+func TestResolveExtends(t *testing.T) {
+	resolved, err := resolveTargetsText(t, `targets:
+  base:
+    src: src
+    platform: avr
+    board: uno
+    flags:
+      global: [-Wall]
+    definitions:
+      target: [DEBUG]
+  mega:
+    extends: base
+    board: mega2560
+    flags:
+      global: [-Wall, -Werror]
+      target: [-O2]
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, `targets:
+  base:
+    src: src
+    platform: avr
+    board: uno
+    flags:
+      global: [-Wall]
+    definitions:
+      target: [DEBUG]
+  mega:
+    src: src
+    platform: avr
+    board: mega2560
+    flags:
+      global: [-Wall, -Werror]
+      target: [-O2]
+    definitions:
+      target: [DEBUG]
+`, resolved)
+}
+
+func TestResolveTemplates(t *testing.T) {
+	resolved, err := resolveTargetsText(t, `templates:
+  common:
+    src: src
+    linker_flags: [-lm]
+  avr:
+    extends: common
+    platform: avr
+    linker_flags: [-lc]
+targets:
+  uno:
+    extends: avr
+    board: uno
+    linker_flags: [-lm, -lprintf]
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, `targets:
+  uno:
+    src: src
+    linker_flags: [-lm, -lc, -lprintf]
+    platform: avr
+    board: uno
+`, resolved)
+}
+
+func TestResolveOverride(t *testing.T) {
+	resolved, err := resolveTargetsText(t, `targets:
+  base:
+    platform: avr
+    boards: [uno, mega2560]
+    flags:
+      global: [-Wall]
+      target: [-O2]
+    run:
+      args: [--verbose]
+      stdin: input.txt
+  nano:
+    extends: base
+    boards: !override [nano]
+    flags:
+      global: !override [-Os]
+    run: !override
+      args: [--quiet]
+  plain:
+    platform: native
+    linker_flags: !override [-lm]
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, `targets:
+  base:
+    platform: avr
+    boards: [uno, mega2560]
+    flags:
+      global: [-Wall]
+      target: [-O2]
+    run:
+      args: [--verbose]
+      stdin: input.txt
+  nano:
+    platform: avr
+    boards: [nano]
+    flags:
+      global: [-Os]
+      target: [-O2]
+    run:
+      args: [--quiet]
+  plain:
+    platform: native
+    linker_flags: [-lm]
+`, resolved)
+}
+
+func TestResolveExtendsErrors(t *testing.T) {
+	tests := []struct {
+		text    string
+		message string
+	}{
+		{`targets:
+  a:
+    extends: a
+`, "3:5: a extends itself"},
+		{`targets:
+  a:
+    extends: b
+  b:
+    extends: a
+`, "3:5: a extends itself"},
+		{`targets:
+  a:
+    extends: missing
+`, "3:14: no target or template named missing"},
+		{`templates:
+  a:
+    src: src
+targets:
+  a:
+    src: src
+  b:
+    extends: a
+`, "8:14: a is both a target and a template"},
+		{`targets:
+  a:
+    extends: [b]
+  b:
+    src: src
+`, "3:14: a must extend a single target or template"},
+	}
+
+	for _, test := range tests {
+		_, err := resolveTargetsText(t, test.text)
+		assert.EqualError(t, err, test.message)
+	}
+}
+
+func TestReadOverrideConfig(t *testing.T) {
+	dir := writeTestConfig(t, `type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.10.0
+targets:
+  base:
+    src: src
+    platform: native
+    linker_flags: [-lm]
+  main:
+    extends: base
+    linker_flags: !override [-lc]
+`)
+
+	defer os.RemoveAll(dir)
+
+	config, err := ReadWioConfig(dir, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-lc"}, config.GetTargets()["main"].GetLinkerFlags())
+
+	config, err = ReadWioConfig(dir, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-lc"}, config.GetTargets()["main"].GetLinkerFlags())
+	assert.Equal(t, "src", config.GetTargets()["main"].GetSource())
+	assert.Equal(t, []string{"-lm"}, config.GetTargets()["base"].GetLinkerFlags())
+}
+
+func TestExpandMatrices(t *testing.T) {
+	tests := []struct {
+		name     string
+		targets  string
+		expanded string
+	}{
+		{"plain target", `main:
+  platform: native
+`, `main:
+  platform: native
+`},
+		{"boards", `tests:
+  platform: avr
+  boards: [uno, mega2560]
+`, `tests__uno:
+  platform: avr
+  board: uno
+  matrix: tests
+tests__mega2560:
+  platform: avr
+  board: mega2560
+  matrix: tests
+`},
+		{"frameworks", `tests:
+  platform: avr
+  board: uno
+  frameworks: [arduino, cosa]
+`, `tests__arduino:
+  platform: avr
+  board: uno
+  framework: arduino
+  matrix: tests
+tests__cosa:
+  platform: avr
+  board: uno
+  framework: cosa
+  matrix: tests
+`},
+		{"boards and frameworks", `tests:
+  platform: avr
+  framework: arduino
+  boards: [uno, nano]
+  frameworks: [arduino, cosa]
+main:
+  platform: native
+`, `tests__uno__arduino:
+  platform: avr
+  framework: arduino
+  board: uno
+  matrix: tests
+tests__uno__cosa:
+  platform: avr
+  framework: cosa
+  board: uno
+  matrix: tests
+tests__nano__arduino:
+  platform: avr
+  framework: arduino
+  board: nano
+  matrix: tests
+tests__nano__cosa:
+  platform: avr
+  framework: cosa
+  board: nano
+  matrix: tests
+main:
+  platform: native
+`},
+	}
+
+	for _, test := range tests {
+		targets := &yamlv3.Node{}
+		assert.NoError(t, yamlv3.Unmarshal([]byte(test.targets), targets), test.name)
+		assert.NoError(t, expandMatrices(documentRoot(targets)), test.name)
+
+		encoded, err := EncodeNode(targets)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expanded, string(encoded), test.name)
+	}
+}
+
+func TestExpandMatricesErrors(t *testing.T) {
+	tests := []struct {
+		targets string
+		message string
+	}{
+		{"tests:\n  boards: []\n", "2:11: boards must be a list of values"},
+		{"tests:\n  boards: uno\n", "2:11: boards must be a list of values"},
+		{"tests:\n  frameworks: [[arduino]]\n", "2:16: frameworks must be a list of values"},
+	}
+
+	for _, test := range tests {
+		targets := &yamlv3.Node{}
+		assert.NoError(t, yamlv3.Unmarshal([]byte(test.targets), targets))
+		assert.EqualError(t, expandMatrices(documentRoot(targets)), test.message)
+	}
+}
+
+func TestReadMatrixConfig(t *testing.T) {
+	dir := writeTestConfig(t, `type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.10.0
+targets:
+  tests:
+    src: tests
+    platform: avr
+    boards: [uno, mega2560]
+`)
+
+	defer os.RemoveAll(dir)
+
+	config, err := ReadWioConfig(dir, true)
+	assert.NoError(t, err)
+	assert.Len(t, config.GetTargets(), 2)
+	for _, board := range []string{"uno", "mega2560"} {
+		target := config.GetTargets()["tests__"+board]
+		assert.Equal(t, board, target.GetBoard())
+		assert.Equal(t, "tests", target.GetMatrix())
+		assert.Equal(t, "tests", target.GetSource())
+	}
+}
@@ -0,0 +1,352 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Problem found while checking wio.yml
+type ConfigError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e ConfigError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// Error pointing at the position of a node in wio.yml
+func nodeError(node *yamlv3.Node, format string, args ...interface{}) ConfigError {
+	return ConfigError{Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)}
+}
+
+// Fields are optional unless tagged with schema:"required". Fields tagged with schema:"internal"
+// are only set while resolving wio.yml and are not part of the schema
+type yamlField struct {
+	name     string
+	required bool
+	internal bool
+	index    int
+}
+
+// Fields of a struct keyed by their yaml name
+func yamlFields(structType reflect.Type) []yamlField {
+	var fields []yamlField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get("yaml")
+		if field.PkgPath != "" || tag == "-" || tag == "" {
+			continue
+		}
+		fields = append(fields, yamlField{
+			name:     strings.Split(tag, ",")[0],
+			required: field.Tag.Get("schema") == "required",
+			internal: field.Tag.Get("schema") == "internal",
+			index:    i,
+		})
+	}
+	return fields
+}
+
+// JSON Schema of wio.yml generated from the config types. Config types are kept in definitions
+// and referenced, so that fields can wrap them in conditional values
+func ConfigSchema() map[string]interface{} {
+	definitions := schemaDefinitions{
+		"condition": map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": func() map[string]interface{} {
+				properties := map[string]interface{}{}
+				for field := range conditionFields {
+					properties[field] = map[string]interface{}{
+						"anyOf": []interface{}{
+							map[string]interface{}{"type": "string"},
+							map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+						},
+					}
+				}
+				return properties
+			}(),
+		},
+	}
+	schema := definitions.structSchema(reflect.TypeOf(ConfigImpl{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "wio.yml"
+	schema["definitions"] = map[string]interface{}(definitions)
+	return schema
+}
+
+// Schemas of config types by type name
+type schemaDefinitions map[string]interface{}
+
+func (definitions schemaDefinitions) typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return definitions.typeSchema(t.Elem())
+	case reflect.Struct:
+		if _, exists := definitions[t.Name()]; !exists {
+			// reserved first so that recursive types reference themselves
+			definitions[t.Name()] = nil
+			definitions[t.Name()] = definitions.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": conditionalField(definitions.typeSchema(t.Elem())),
+		}
+	case reflect.Slice:
+		item := definitions.typeSchema(t.Elem())
+		return map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"anyOf": []interface{}{
+					item,
+					conditionalSchema(valueTag, item),
+					conditionalSchema(valuesTag, map[string]interface{}{"type": "array", "items": item}),
+				},
+			},
+		}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+func (definitions schemaDefinitions) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{
+		whenTag: map[string]interface{}{"$ref": "#/definitions/condition"},
+	}
+	var required []string
+	for _, field := range yamlFields(t) {
+		if field.internal {
+			continue
+		}
+		properties[field.name] = conditionalField(definitions.typeSchema(t.Field(field.index).Type))
+		if field.required {
+			required = append(required, field.name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// Values of fields and maps can be given as { when: ..., value: ... } and { when: ..., values: ... } too
+func conditionalField(value map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"anyOf": []interface{}{
+			value,
+			map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": false,
+				"required":             []string{whenTag},
+				"minProperties":        2,
+				"maxProperties":        2,
+				"properties": map[string]interface{}{
+					whenTag:   map[string]interface{}{"$ref": "#/definitions/condition"},
+					valueTag:  value,
+					valuesTag: value,
+				},
+			},
+		},
+	}
+}
+
+func conditionalSchema(key string, value map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{whenTag, key},
+		"properties": map[string]interface{}{
+			whenTag: map[string]interface{}{"$ref": "#/definitions/condition"},
+			key:     value,
+		},
+	}
+}
+
+// Strictly checks a wio.yml document against the config types. Unknown fields, wrong types and
+// missing required fields are reported with their line and column
+func CheckWioDocument(document *yamlv3.Node) []ConfigError {
+	return checkDocument(document, false)
+}
+
+// Checks a resolved wio.yml document, which can have internal fields and no conditions
+func CheckResolvedDocument(document *yamlv3.Node) []ConfigError {
+	return checkDocument(document, true)
+}
+
+// Resolves targets, conditions and variables of a wio.yml document in place. Problems are
+// returned as ConfigError
+func ResolveWioDocument(document *yamlv3.Node, dir string) error {
+	return resolveConfig(document, dir, nil)
+}
+
+type documentChecker struct {
+	resolved bool
+	errs     []ConfigError
+}
+
+func checkDocument(document *yamlv3.Node, resolved bool) []ConfigError {
+	root := documentRoot(document)
+	if root == nil {
+		return []ConfigError{{Line: 1, Column: 1, Message: "wio.yml is empty"}}
+	}
+	checker := &documentChecker{resolved: resolved}
+	checker.checkNode(root, reflect.TypeOf(ConfigImpl{}), "")
+	return checker.errs
+}
+
+func (checker *documentChecker) report(node *yamlv3.Node, format string, args ...interface{}) {
+	checker.errs = append(checker.errs, nodeError(node, format, args...))
+}
+
+func (checker *documentChecker) checkNode(node *yamlv3.Node, t reflect.Type, path string) {
+	report := checker.report
+	describe := func() string {
+		if path == "" {
+			return "wio.yml"
+		}
+		return path
+	}
+
+	if node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yamlv3.ScalarNode && node.ShortTag() == "!!null" {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		checker.checkNode(node, t.Elem(), path)
+
+	case reflect.Struct:
+		if node.Kind != yamlv3.MappingNode {
+			report(node, "%s must be a map", describe())
+			return
+		}
+		fields := map[string]yamlField{}
+		for _, field := range yamlFields(t) {
+			if !field.internal || checker.resolved {
+				fields[field.name] = field
+			}
+		}
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			seen[key.Value] = true
+			if key.Value == whenTag {
+				checker.checkCondition(value)
+			} else if field, exists := fields[key.Value]; exists {
+				checker.checkValue(value, t.Field(field.index).Type, joinPath(path, key.Value))
+			} else {
+				report(key, "unknown field %s in %s", key.Value, describe())
+			}
+		}
+		for _, field := range yamlFields(t) {
+			if field.required && !seen[field.name] {
+				report(node, "%s is missing required field %s", describe(), field.name)
+			}
+		}
+
+	case reflect.Map:
+		if node.Kind != yamlv3.MappingNode {
+			report(node, "%s must be a map", describe())
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checker.checkValue(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+
+	case reflect.Slice:
+		if node.Kind != yamlv3.SequenceNode {
+			report(node, "%s must be a list", describe())
+			return
+		}
+		for _, item := range node.Content {
+			if value, splice := conditionalValue(item); value != nil {
+				checker.checkCondition(mappingValue(item, whenTag))
+				if splice {
+					checker.checkNode(value, t, path)
+				} else {
+					checker.checkNode(value, t.Elem(), path)
+				}
+			} else {
+				checker.checkNode(item, t.Elem(), path)
+			}
+		}
+
+	default:
+		if node.Kind != yamlv3.ScalarNode {
+			report(node, "%s must be a single value", describe())
+			return
+		}
+		// interpolated values and legacy OS selectors are only known once resolved
+		if strings.Contains(node.Value, "$") {
+			return
+		}
+		if err := node.Decode(reflect.New(t).Interface()); err != nil {
+			report(node, "%s has invalid value %s, expected %s", describe(), node.Value, t.Kind().String())
+		}
+	}
+}
+
+// Checks the value of a field or a map, which is replaced by its conditional value when resolved
+func (checker *documentChecker) checkValue(node *yamlv3.Node, t reflect.Type, path string) {
+	if value, _ := conditionalValue(node); value != nil {
+		checker.checkCondition(mappingValue(node, whenTag))
+		checker.checkNode(value, t, path)
+		return
+	}
+	checker.checkNode(node, t, path)
+}
+
+func (checker *documentChecker) checkCondition(when *yamlv3.Node) {
+	report := checker.report
+
+	if when.Kind != yamlv3.MappingNode {
+		report(when, "when must be a map of conditions")
+		return
+	}
+	for i := 0; i+1 < len(when.Content); i += 2 {
+		field, condition := when.Content[i], when.Content[i+1]
+		if !conditionFields[field.Value] {
+			report(field, "unknown condition %s", field.Value)
+			continue
+		}
+
+		values := []*yamlv3.Node{condition}
+		if condition.Kind == yamlv3.SequenceNode {
+			values = condition.Content
+		}
+		for _, value := range values {
+			if value.Kind != yamlv3.ScalarNode {
+				report(value, "condition %s must be a value or a list of values", field.Value)
+			}
+		}
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// published schema has to be regenerated with wio validate --schema when config types change
+func TestPublishedSchema(t *testing.T) {
+	published, err := ioutil.ReadFile("../../assets/configurations/wio.schema.json")
+	assert.NoError(t, err)
+
+	generated, err := json.MarshalIndent(ConfigSchema(), "", "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, string(generated)+"\n", string(published))
+}
+
+func TestConfigSchema(t *testing.T) {
+	schema := ConfigSchema()
+	assert.Equal(t, []string{"type", "project"}, schema["required"])
+
+	definitions := schema["definitions"].(map[string]interface{})
+	targets := schema["properties"].(map[string]interface{})["targets"].(map[string]interface{})["anyOf"].([]interface{})[0]
+	targetRef := targets.(map[string]interface{})["additionalProperties"].(map[string]interface{})["anyOf"].([]interface{})[0]
+	assert.Equal(t, map[string]interface{}{"$ref": "#/definitions/TargetImpl"}, targetRef)
+
+	target := definitions["TargetImpl"].(map[string]interface{})
+	assert.NotContains(t, target["properties"], "matrix")
+	assert.NotContains(t, target, "required")
+}
+
+func TestCheckWioDocument(t *testing.T) {
+	text := `type: app
+project:
+  compile_options:
+    wio_version: 0.10.0
+targets:
+  main:
+    platform: native
+    matrix: main
+dependencies:
+  local:
+    path: ../local
+  remote:
+    git: https://github.com/wio/remote
+    unknown: true
+`
+	document := &yamlv3.Node{}
+	assert.NoError(t, yamlv3.Unmarshal([]byte(text), document))
+
+	assert.Equal(t, []ConfigError{
+		{Line: 3, Column: 3, Message: "project is missing required field name"},
+		{Line: 8, Column: 5, Message: "unknown field matrix in targets.main"},
+		{Line: 14, Column: 5, Message: "unknown field unknown in dependencies.remote"},
+	}, CheckWioDocument(document))
+
+	assert.Equal(t, []ConfigError{
+		{Line: 3, Column: 3, Message: "project is missing required field name"},
+		{Line: 14, Column: 5, Message: "unknown field unknown in dependencies.remote"},
+	}, CheckResolvedDocument(document))
+}
+
+func TestCheckConditionalValues(t *testing.T) {
+	text := `type: app
+project:
+  name: app
+  compile_options:
+    wio_version: 0.10.0
+targets:
+  main:
+    platform: native
+    board:
+      when: {os: linux}
+      value: uno
+    boards:
+      when: {os: windows}
+      values: [uno, mega]
+dependencies:
+  bar:
+    when: {os: linux}
+    value:
+      version: 1.0.0
+  baz:
+    when: {os: linux}
+    value: [1.0.0]
+  qux:
+    when: {os: nowhere, board: uno}
+    value:
+      version: 1.0.0
+      unknown: true
+`
+	document := &yamlv3.Node{}
+	assert.NoError(t, yamlv3.Unmarshal([]byte(text), document))
+
+	assert.Equal(t, []ConfigError{
+		{Line: 22, Column: 12, Message: "dependencies.baz must be a map"},
+		{Line: 27, Column: 7, Message: "unknown field unknown in dependencies.qux"},
+	}, CheckWioDocument(document))
+}
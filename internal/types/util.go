@@ -2,10 +2,8 @@ package types
 
 import (
 	"bufio"
-	"fmt"
 	"regexp"
 	"strings"
-	"time"
 	"wio/internal/config/meta"
 	"wio/internal/constants"
 	"wio/pkg/npm/semver"
@@ -13,98 +11,82 @@ import (
 	"wio/pkg/util/sys"
 
 	yaml "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
-var configCategoryTag = regexp.MustCompile(`^[\s\w]*[a-z]:\s*$`)
-
-func resolveVariables(line string, projectPath string) string {
-	return strings.Replace(line, "$(PROJECT_PATH)", projectPath, -1)
+// Reads wio.yml of a project. Fulfilling resolves targets, conditions and variables
+func ReadWioConfig(dir string, fulfill bool) (Config, error) {
+	return readWioConfig(dir, fulfill, nil)
 }
 
-// resolves operating system specific flags and definitions
-func resolveOSSpecific(line string) (string, error) {
-	osName := sys.GetOS()
+// Reads and fulfills wio.yml of a project for the target being built. Conditions outside of
+// targets are evaluated against platform, framework and board of the target
+func ReadTargetConfig(dir string, target Target) (Config, error) {
+	return readWioConfig(dir, true, map[string]string{
+		"platform":  target.GetPlatform(),
+		"framework": target.GetFramework(),
+		"board":     target.GetBoard(),
+	})
+}
 
-	const windowsStr = "$windows("
-	const darwinStr = "$darwin("
-	const linuxStr = "$linux("
+// Reads wio.yml of a project as a node tree, fulfilling resolves targets, conditions and variables
+func ReadWioDocument(dir string, fulfill bool) (*yamlv3.Node, error) {
+	document, _, err := readWioDocument(dir, fulfill, nil)
+	return document, err
+}
 
-	diffOsSystemMatch := regexp.MustCompile(
-		strings.Replace(`\$(darwin|windows|linux)\(([^,])*\)`, osName, "", 1))
+func readWioDocument(dir string, fulfill bool, context map[string]string) (*yamlv3.Node, []byte, error) {
+	path := sys.Path(dir, sys.Config)
+	if !sys.Exists(path) {
+		return nil, nil, util.Error("path does not contain a wio.yml: %s", dir)
+	}
 
-	i := 0
-	for end := time.Now().Add(time.Second); ; {
-		if i&0x0f == 0 { // Check in every 16th iteration
-			if time.Now().After(end) {
-				return "", util.Error("error occured while fulfulling OS variables")
-			}
-		}
+	text, err := sys.NormalIO.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		if strings.Contains(line, windowsStr) || strings.Contains(line, darwinStr) || strings.Contains(line, linuxStr) {
-			line = diffOsSystemMatch.ReplaceAllString(line, "")
-			line = strings.Replace(line, ",", "", -1)
-			line = strings.TrimSuffix(line, " ")
+	document := &yamlv3.Node{}
+	if err = yamlv3.Unmarshal(text, document); err != nil {
+		return nil, nil, util.Error("%s: %s", path, err.Error())
+	}
 
-			if strings.Contains(line, fmt.Sprintf("$%s(", osName)) {
-				line = strings.Replace(line, "$"+osName+"(", "", 1)
-				line = line[:len(line)-1]
+	if fulfill {
+		if err := resolveConfig(document, dir, context); err != nil {
+			if _, positioned := err.(ConfigError); positioned {
+				return nil, nil, util.Error("%s:%s", path, err.Error())
 			}
-		} else {
-			break
+			return nil, nil, util.Error("%s: %s", path, err.Error())
 		}
-
-		i++
 	}
-
-	return line, nil
+	return document, text, nil
 }
 
-func fulfillVariables(data []byte, projectPath string) ([]byte, error) {
-	scanner := bufio.NewScanner(strings.NewReader(string(data)))
-	newStr := ""
-
-	for scanner.Scan() {
-		if configCategoryTag.MatchString(scanner.Text()) {
-			newStr += scanner.Text() + "\n"
-			continue
-		}
-
-		// resolve operating system constrains
-		osStr, err := resolveOSSpecific(scanner.Text())
-		if err != nil {
-			return nil, err
-		}
-
-		// resolve PROJECT_PATH variable
-		variableStr := resolveVariables(osStr, projectPath)
-
-		newStr += variableStr + "\n"
+// Finds a node inside of a document by the keys leading to it
+func FindNode(document *yamlv3.Node, keys ...string) *yamlv3.Node {
+	node := documentRoot(document)
+	for _, key := range keys {
+		node = mappingValue(node, key)
 	}
-
-	return []byte(newStr), nil
+	return node
 }
 
-func ReadWioConfig(dir string, fulfill bool) (Config, error) {
-	path := sys.Path(dir, sys.Config)
-	if !sys.Exists(path) {
-		return nil, util.Error("path does not contain a wio.yml: %s", dir)
-	}
-
-	text, err := sys.NormalIO.ReadFile(path)
+func readWioConfig(dir string, fulfill bool, context map[string]string) (Config, error) {
+	document, text, err := readWioDocument(dir, fulfill, context)
 	if err != nil {
 		return nil, err
 	}
-
-	if fulfill {
-		text, err = fulfillVariables(text, dir)
-		if err != nil {
-			return nil, err
-		}
+	if !fulfill {
+		document = copyNode(document)
+		flattenConditions(document)
+		clearOverrides(document)
 	}
 
 	ret := &ConfigImpl{}
-	if err = yaml.Unmarshal(text, ret); err != nil {
-		return nil, err
+	if document.Kind != 0 {
+		if err = document.Decode(ret); err != nil {
+			return nil, err
+		}
 	}
 
 	// Check to give error on older versions of wio
@@ -0,0 +1,130 @@
+package types
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+)
+
+// wio-workspace.yml lists directories of the projects that are part of a workspace. Members can
+// be globs like packages/*
+type WorkspaceImpl struct {
+	Members []string `yaml:"members"`
+}
+
+// Project that is part of a workspace
+type WorkspaceMember struct {
+	Path   string
+	Config Config
+}
+
+// Finds root of the workspace a directory is part of by looking for wio-workspace.yml in the
+// directory and its parents. Empty string is returned when there is no workspace
+func FindWorkspace(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if sys.Exists(sys.Path(dir, sys.Workspace)) {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Reads members of a workspace in the order they are listed
+func ReadWorkspace(root string) ([]*WorkspaceMember, error) {
+	workspace := &WorkspaceImpl{}
+	if err := sys.NormalIO.ParseYml(sys.Path(root, sys.Workspace), workspace); err != nil {
+		return nil, util.Error("%s: %s", sys.Path(root, sys.Workspace), err.Error())
+	}
+
+	var members []*WorkspaceMember
+	seen := map[string]bool{}
+	names := map[string]string{}
+	for _, pattern := range workspace.Members {
+		matches, err := filepath.Glob(sys.Path(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, util.Error("invalid workspace member %s: %s", pattern, err.Error())
+		}
+		if len(matches) <= 0 {
+			return nil, util.Error("workspace member %s does not exist", pattern)
+		}
+		sort.Strings(matches)
+
+		for _, match := range matches {
+			if seen[match] || !sys.Exists(sys.Path(match, sys.Config)) {
+				continue
+			}
+			seen[match] = true
+
+			config, err := ReadWioConfig(match, true)
+			if err != nil {
+				return nil, err
+			}
+			if other, exists := names[config.GetName()]; exists {
+				return nil, util.Error("workspace members %s and %s are both named %s", other, match,
+					config.GetName())
+			}
+			names[config.GetName()] = match
+			members = append(members, &WorkspaceMember{Path: match, Config: config})
+		}
+	}
+	return members, nil
+}
+
+// Orders members so that every member comes after the members it depends on
+func OrderWorkspace(members []*WorkspaceMember) ([]*WorkspaceMember, error) {
+	byName := map[string]*WorkspaceMember{}
+	for _, member := range members {
+		byName[member.Config.GetName()] = member
+	}
+
+	var ordered []*WorkspaceMember
+	done := map[*WorkspaceMember]bool{}
+	visiting := map[*WorkspaceMember]bool{}
+
+	var visit func(member *WorkspaceMember, path []string) error
+	visit = func(member *WorkspaceMember, path []string) error {
+		if done[member] {
+			return nil
+		}
+		path = append(path, member.Config.GetName())
+		if visiting[member] {
+			return util.Error("workspace members depend on each other: %s", strings.Join(path, " -> "))
+		}
+		visiting[member] = true
+
+		var names []string
+		for name := range member.Config.GetDependencies() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if dependency, exists := byName[name]; exists {
+				if err := visit(dependency, path); err != nil {
+					return err
+				}
+			}
+		}
+
+		delete(visiting, member)
+		done[member] = true
+		ordered = append(ordered, member)
+		return nil
+	}
+
+	for _, member := range members {
+		if err := visit(member, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
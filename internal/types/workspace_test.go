@@ -0,0 +1,109 @@
+package types
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Writes a workspace with a package for each of the members, packages depend on the names listed
+func writeTestWorkspace(t *testing.T, members string, packages map[string][]string) string {
+	dir, err := ioutil.TempDir("", "wio-workspace")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(sys.Path(dir, sys.Workspace), []byte(members), 0644))
+
+	for path, dependencies := range packages {
+		text := "type: pkg\nproject:\n  name: " + filepath.Base(path) +
+			"\n  version: 1.0.0\n  compile_options:\n    wio_version: 0.10.0\n"
+		if len(dependencies) > 0 {
+			text += "dependencies:\n"
+			for _, dependency := range dependencies {
+				text += "  " + dependency + ":\n    version: ^1.0.0\n"
+			}
+		}
+		assert.NoError(t, os.MkdirAll(sys.Path(dir, path), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(sys.Path(dir, path, sys.Config), []byte(text), 0644))
+	}
+	return dir
+}
+
+func memberNames(members []*WorkspaceMember) []string {
+	var names []string
+	for _, member := range members {
+		names = append(names, member.Config.GetName())
+	}
+	return names
+}
+
+func TestFindWorkspace(t *testing.T) {
+	dir := writeTestWorkspace(t, "members: [packages/*]\n", map[string][]string{"packages/a": nil})
+	defer os.RemoveAll(dir)
+
+	root, err := FindWorkspace(sys.Path(dir, "packages", "a"))
+	assert.NoError(t, err)
+	assert.Equal(t, dir, root)
+
+	outside, err := ioutil.TempDir("", "wio-project")
+	assert.NoError(t, err)
+	defer os.RemoveAll(outside)
+	root, err = FindWorkspace(outside)
+	assert.NoError(t, err)
+	assert.Equal(t, "", root)
+}
+
+func TestReadWorkspace(t *testing.T) {
+	dir := writeTestWorkspace(t, "members: [tools, packages/*, packages/b]\n", map[string][]string{
+		"packages/b": nil, "packages/a": nil, "tools": nil, "packages/docs": nil,
+	})
+	defer os.RemoveAll(dir)
+	os.Remove(sys.Path(dir, "packages", "docs", sys.Config))
+
+	// members are kept in the order they are listed, globs are sorted and directories
+	// without wio.yml are skipped
+	members, err := ReadWorkspace(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tools", "a", "b"}, memberNames(members))
+	assert.Equal(t, sys.Path(dir, "tools"), members[0].Path)
+}
+
+func TestReadWorkspaceErrors(t *testing.T) {
+	dir := writeTestWorkspace(t, "members: [packages/*, missing]\n", map[string][]string{"packages/a": nil})
+	defer os.RemoveAll(dir)
+	_, err := ReadWorkspace(dir)
+	assert.EqualError(t, err, "workspace member missing does not exist")
+
+	dir = writeTestWorkspace(t, "members: [a, b/a]\n", map[string][]string{"a": nil, "b/a": nil})
+	defer os.RemoveAll(dir)
+	_, err = ReadWorkspace(dir)
+	assert.EqualError(t, err, "workspace members "+sys.Path(dir, "a")+" and "+sys.Path(dir, "b", "a")+
+		" are both named a")
+}
+
+func TestOrderWorkspace(t *testing.T) {
+	dir := writeTestWorkspace(t, "members: [app, net, log, fmt]\n", map[string][]string{
+		"app": {"net", "log", "json"}, "net": {"log"}, "log": {"fmt"}, "fmt": nil,
+	})
+	defer os.RemoveAll(dir)
+
+	members, err := ReadWorkspace(dir)
+	assert.NoError(t, err)
+	ordered, err := OrderWorkspace(members)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fmt", "log", "net", "app"}, memberNames(ordered))
+}
+
+func TestOrderWorkspaceCycle(t *testing.T) {
+	dir := writeTestWorkspace(t, "members: [a, b, c]\n", map[string][]string{
+		"a": {"b"}, "b": {"c"}, "c": {"a"},
+	})
+	defer os.RemoveAll(dir)
+
+	members, err := ReadWorkspace(dir)
+	assert.NoError(t, err)
+	_, err = OrderWorkspace(members)
+	assert.EqualError(t, err, "workspace members depend on each other: a -> b -> c -> a")
+}
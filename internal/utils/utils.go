@@ -5,3 +5,12 @@ import "wio/pkg/util/sys"
 func BuildPath(projectPath string) string {
 	return sys.Path(projectPath, sys.WioFolder, sys.TargetDir)
 }
+
+// Build directory of a target. Each profile gets its own directory next to the one of the target as
+// target@profile, so that cleaning one build leaves the others alone. Matrix targets already use __
+func TargetPath(projectPath string, targetName string, profileName string) string {
+	if profileName == "" {
+		return sys.Path(BuildPath(projectPath), targetName)
+	}
+	return sys.Path(BuildPath(projectPath), targetName+"@"+profileName)
+}
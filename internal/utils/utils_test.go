@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTargetPath(t *testing.T) {
+	targets := sys.Path("project", sys.WioFolder, sys.TargetDir)
+	main := TargetPath("project", "main", "")
+	debug := TargetPath("project", "main", "debug")
+	assert.Equal(t, sys.Path(targets, "main"), main)
+	assert.Equal(t, sys.Path(targets, "main@debug"), debug)
+
+	// profile builds are not inside of the default build, which a hard clean removes
+	assert.Equal(t, filepath.Dir(main), filepath.Dir(debug))
+	assert.False(t, strings.HasPrefix(debug, main+string(filepath.Separator)))
+	assert.NotEqual(t, TargetPath("project", "main__uno", ""), TargetPath("project", "main", "uno"))
+}
@@ -2,8 +2,11 @@ package downloader
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"wio/internal/config/root"
+	"wio/internal/types"
+	"wio/pkg/npm/resolve"
 	"wio/pkg/util"
 	"wio/pkg/util/sys"
 	"wio/pkg/util/template"
@@ -18,6 +21,7 @@ const WioConfigSet = "set({{VAR_NAME}} \"{{VAR_VALUE}}\")"
 
 type ModuleData struct {
 	Name        string `json:"name"`
+	Version     string `json:"version"`
 	Description string `json:"description"`
 
 	Author  interface{} `json:"author"`
@@ -31,44 +35,52 @@ type Downloader interface {
 	DownloadModule(path, url, reference string, retool bool) (string, error)
 }
 
-func DownloadToolchain(toolchainLink string, retool bool) (string, error) {
+// Splits a toolchain link into the toolchain name and ref. Aliases are replaced by the name of
+// their toolchain and links with a host are git repositories
+func ParseToolchainLink(toolchainLink string) (name string, ref string, isGit bool, err error) {
 	// link must be plain without these accessors
 	if strings.Contains(toolchainLink, "https://") || strings.Contains(toolchainLink, "http://") {
-		return "", util.Error("toolchain link provided must be without https or http, ex: github.com/foo")
+		return "", "", false, util.Error("toolchain link provided must be without https or http, ex: github.com/foo")
 	}
 
-	toolchainName, toolchainRef := func() (string, string) {
-		split := strings.Split(toolchainLink, ":")
-
-		if len(split) <= 1 {
-			return split[0], ""
-		} else {
-			return split[0], split[1]
-		}
-	}()
+	split := strings.Split(toolchainLink, ":")
+	name = split[0]
+	if len(split) > 1 {
+		ref = split[1]
+	}
 
 	// use alias
-	toolchainName = func() string {
-		if val, exists := SupportedToolchains[toolchainName]; exists {
-			return val
-		} else {
-			return toolchainName
-		}
-	}()
-
-	var d Downloader
+	if val, exists := SupportedToolchains[name]; exists {
+		name = val
+	}
 
 	// this is not a valid name so it must be a url
-	if strings.Contains(toolchainName, ".") && strings.Contains(toolchainName, "/") {
-		d = GitDownloader{}
+	isGit = strings.Contains(name, ".") && strings.Contains(name, "/")
+	return name, ref, isGit, nil
+}
+
+// Downloads a toolchain unless it is already there and returns its path. Versions toolchains
+// resolve to are read from and recorded in lock
+func DownloadToolchain(toolchainLink string, lock *types.LockImpl, retool bool) (string, error) {
+	toolchainName, toolchainRef, isGit, err := ParseToolchainLink(toolchainLink)
+	if err != nil {
+		return "", err
+	}
+	if lock == nil {
+		lock = &types.LockImpl{}
+	}
+
+	var d Downloader
+	if isGit {
+		d = GitDownloader{Lock: lock}
 	} else {
-		d = NpmDownloader{}
+		d = NpmDownloader{Lock: lock}
 	}
 
 	if path, err := d.DownloadModule(root.GetToolchainPath(), toolchainName, toolchainRef, retool); err != nil {
 		return "", err
 	} else {
-		_, err := createDepAttributes(path, "")
+		_, err := createDepAttributes(path, "", lock)
 		if err != nil {
 			return "", err
 		}
@@ -77,7 +89,66 @@ func DownloadToolchain(toolchainLink string, retool bool) (string, error) {
 	}
 }
 
-func createDepAttributes(file string, configData string) (string, error) {
+// Path of the toolchain a link resolves to with the versions and commits in lock. Empty when the
+// toolchain is not locked yet
+func LockedToolchainPath(toolchainLink string, lock *types.LockImpl) string {
+	name, ref, isGit, err := ParseToolchainLink(toolchainLink)
+	if err != nil {
+		return ""
+	}
+	if isGit {
+		if util.IsEmptyString(ref) {
+			ref = DefaultRef
+		}
+		if commit := lock.ToolchainVersion(name, ref); commit != "" {
+			return ToolchainClonePath(root.GetToolchainPath(), name, commit)
+		}
+		return ""
+	}
+	if util.IsEmptyString(ref) {
+		ref = resolve.Latest
+	}
+	if version := lock.ToolchainVersion(name, ref); version != "" {
+		return sys.Path(root.GetToolchainPath(), name) + "__" + version
+	}
+	return ""
+}
+
+// Paths of a toolchain followed by the paths of the dependency versions locked for it. Nil when a
+// dependency is not locked, like for toolchains downloaded before their dependencies were locked
+func ToolchainPaths(path string, lock *types.LockImpl) []string {
+	paths := []string{path}
+	moduleData := &ModuleData{}
+	if err := sys.NormalIO.ParseJson(sys.Path(path, "package.json"), moduleData); err != nil {
+		return paths
+	}
+	for name, query := range moduleData.Dependencies {
+		version := lock.ToolchainVersion(name, query)
+		if version == "" {
+			return nil
+		}
+		dependencyPaths := ToolchainPaths(sys.Path(root.GetToolchainPath(), name+"__"+version), lock)
+		if dependencyPaths == nil {
+			return nil
+		}
+		paths = append(paths, dependencyPaths...)
+	}
+	sort.Strings(paths[1:])
+	return paths
+}
+
+// Whether a toolchain and the dependency versions locked for it are installed
+func toolchainInstalled(path string, lock *types.LockImpl) bool {
+	paths := ToolchainPaths(path, lock)
+	for _, path := range paths {
+		if !sys.Exists(path) {
+			return false
+		}
+	}
+	return paths != nil
+}
+
+func createDepAttributes(file string, configData string, lock *types.LockImpl) (string, error) {
 	wioConfigPath := sys.Path(file, "WioConfig.cmake")
 
 	moduleData := &ModuleData{}
@@ -86,7 +157,12 @@ func createDepAttributes(file string, configData string) (string, error) {
 		return "", err
 	}
 
-	for name, version := range moduleData.Dependencies {
+	for name, query := range moduleData.Dependencies {
+		// dependencies can be ranges, use the version they resolved to
+		version := lock.ToolchainVersion(name, query)
+		if version == "" {
+			version = installedVersion(name, query)
+		}
 		depPath := sys.Path(root.GetToolchainPath(), name+"__"+version)
 
 		configData += template.Replace(WioConfigSet, map[string]string{
@@ -98,7 +174,7 @@ func createDepAttributes(file string, configData string) (string, error) {
 			"VAR_VALUE": version,
 		}) + "\n"
 
-		returnedData, err := createDepAttributes(depPath, "")
+		returnedData, err := createDepAttributes(depPath, "", lock)
 		configData += returnedData
 		if err != nil {
 			return configData, err
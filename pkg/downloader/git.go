@@ -2,7 +2,12 @@ package downloader
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"wio/internal/env"
+	"wio/internal/types"
 	"wio/pkg/log"
 	"wio/pkg/util"
 	"wio/pkg/util/sys"
@@ -11,49 +16,48 @@ import (
 	"gopkg.in/src-d/go-git.v4/plumbing"
 )
 
-type GitDownloader struct{}
+type GitDownloader struct {
+	Lock *types.LockImpl
+}
 
 const (
 	Protocol   = "https"
 	DefaultRef = "master"
 )
 
+// Downloads a toolchain at the commit locked for the url and reference. Without a locked commit or
+// when retooling, the commit the reference points to is downloaded and locked. Toolchains are kept in
+// folders by commit, so that projects locked to different commits can share the toolchain directory
 func (gitDownloader GitDownloader) DownloadModule(path, url, reference string, retool bool) (string, error) {
 	log.Write(log.Cyan, "Fetching toolchain using git from ")
 	log.Write(log.Green, url)
 	log.Write(log.Cyan, "... ")
 
-	cloneOptions := &git.CloneOptions{
-		URL:               fmt.Sprintf("%s://%s", Protocol, url),
-		Progress:          os.Stdout,
-		Depth:             1,
-		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
+	lockReference := reference
+	if util.IsEmptyString(lockReference) {
+		lockReference = DefaultRef
 	}
-
-	if !util.IsEmptyString(reference) {
-		cloneOptions.ReferenceName = plumbing.ReferenceName(reference)
-	} else {
-		reference = DefaultRef
+	commit := ""
+	if !retool {
+		commit = gitDownloader.Lock.ToolchainVersion(url, lockReference)
 	}
 
-	clonePath := fmt.Sprintf("%s__%s", sys.Path(path, url), reference)
-
-	if sys.Exists(clonePath) && !retool {
+	clonePath := ToolchainClonePath(path, url, commit)
+	if commit != "" && sys.Exists(clonePath) {
 		log.Writeln(log.Green, "already exists")
-		return clonePath, nil
-	} else if sys.Exists(clonePath) && retool {
-		os.RemoveAll(clonePath)
-		log.Writeln(log.Green, "retooling")
 	} else {
-		log.Writeln(log.Green, "downloading")
-	}
-
-	_, err := git.PlainClone(clonePath, false, cloneOptions)
-
-	if err != nil {
-		os.RemoveAll(clonePath)
-		return "", util.Error("toolchain could not be downloaded, check url and reference")
+		if retool {
+			log.Writeln(log.Green, "retooling")
+		} else {
+			log.Writeln(log.Green, "downloading")
+		}
+		var err error
+		if commit, err = cloneToolchain(path, url, reference, commit, retool); err != nil {
+			return "", err
+		}
+		clonePath = ToolchainClonePath(path, url, commit)
 	}
+	gitDownloader.Lock.SetToolchainVersion(url, lockReference, commit)
 
 	moduleData := &ModuleData{}
 
@@ -62,10 +66,110 @@ func (gitDownloader GitDownloader) DownloadModule(path, url, reference string, r
 	}
 
 	for name, version := range moduleData.Dependencies {
-		if _, err := DownloadToolchain(fmt.Sprintf("%s:%s", name, version), retool); err != nil {
+		if _, err := DownloadToolchain(fmt.Sprintf("%s:%s", name, version), gitDownloader.Lock, retool); err != nil {
 			return "", err
 		}
 	}
 
 	return clonePath, nil
 }
+
+// Folder of a toolchain from git checked out at commit
+func ToolchainClonePath(path, url, commit string) string {
+	return fmt.Sprintf("%s__%s", sys.Path(path, url), commit)
+}
+
+// Clones a toolchain and checks out commit, or the commit reference points to without one. The
+// clone is moved to the folder of the commit once it is checked out and the commit is returned
+func cloneToolchain(path, url, reference, commit string, replace bool) (string, error) {
+	parent := filepath.Dir(sys.Path(path, url))
+	if err := os.MkdirAll(parent, os.ModePerm); err != nil {
+		return "", err
+	}
+	downloadPath, err := ioutil.TempDir(parent, filepath.Base(url)+".download")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(downloadPath)
+
+	cloneOptions := &git.CloneOptions{
+		URL:               cloneUrl(url),
+		Progress:          os.Stdout,
+		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
+	}
+	if commit != "" {
+		// the locked commit can be anywhere in the history, so everything is fetched
+		cloneOptions.NoCheckout = true
+		cloneOptions.Tags = git.AllTags
+	} else {
+		cloneOptions.Depth = 1
+		if !util.IsEmptyString(reference) {
+			cloneOptions.ReferenceName = plumbing.ReferenceName(reference)
+		}
+	}
+
+	repo, err := git.PlainClone(downloadPath, false, cloneOptions)
+	if err != nil {
+		return "", util.Error("toolchain could not be downloaded, check url and reference")
+	}
+	if commit != "" {
+		if err := checkoutCommit(repo, commit); err != nil {
+			return "", util.Error("toolchain %s could not be checked out at commit %s locked in wio.lock: %s",
+				url, commit, err.Error())
+		}
+	} else {
+		head, err := repo.Head()
+		if err != nil {
+			return "", err
+		}
+		commit = head.Hash().String()
+	}
+
+	clonePath := ToolchainClonePath(path, url, commit)
+	if sys.Exists(clonePath) {
+		if !replace {
+			return commit, nil
+		}
+		if err := os.RemoveAll(clonePath); err != nil {
+			return "", err
+		}
+	}
+	return commit, os.Rename(downloadPath, clonePath)
+}
+
+// Checks out a commit along with the submodules at the commits it points to
+func checkoutCommit(repo *git.Repository, commit string) error {
+	hash, err := repo.ResolveRevision(plumbing.Revision(commit))
+	if err != nil {
+		return util.Error("commit %s does not exist", commit)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: *hash, Force: true}); err != nil {
+		return err
+	}
+	submodules, err := worktree.Submodules()
+	if err != nil {
+		return err
+	}
+	return submodules.Update(&git.SubmoduleUpdateOptions{
+		Init:              true,
+		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
+	})
+}
+
+// Url to clone a toolchain from. With a mirror, toolchains are cloned from the mirror url followed
+// by the toolchain url, or from a repository at that path when the mirror is a directory
+func cloneUrl(url string) string {
+	mirror := env.GetToolchainMirror()
+	switch {
+	case mirror == "":
+		return fmt.Sprintf("%s://%s", Protocol, url)
+	case strings.HasPrefix(mirror, "http://") || strings.HasPrefix(mirror, "https://"):
+		return strings.TrimSuffix(mirror, "/") + "/" + url
+	default:
+		return sys.Path(mirror, url)
+	}
+}
@@ -0,0 +1,95 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+	"wio/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const gitToolchainUrl = "example.com/wio/toolchain"
+
+// Creates a toolchain repository in a mirror directory with two commits on master and returns
+// both commits
+func createToolchainRepo(t *testing.T, mirror string) (string, string) {
+	src := filepath.Join(mirror, gitToolchainUrl)
+	repo, err := git.PlainInit(src, false)
+	assert.NoError(t, err)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	signature := &object.Signature{Name: "wio", Email: "wio@example.com", When: time.Now()}
+
+	commit := func(version string) string {
+		text := []byte(fmt.Sprintf(`{"name": "toolchain", "version": "%s"}`, version))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "package.json"), text, 0644))
+		_, err := worktree.Add("package.json")
+		assert.NoError(t, err)
+		hash, err := worktree.Commit(version, &git.CommitOptions{Author: signature})
+		assert.NoError(t, err)
+		return hash.String()
+	}
+	return commit("1.0.0"), commit("1.1.0")
+}
+
+func toolchainVersion(t *testing.T, path string) string {
+	data, err := ioutil.ReadFile(filepath.Join(path, "package.json"))
+	assert.NoError(t, err)
+	moduleData := &ModuleData{}
+	assert.NoError(t, json.Unmarshal(data, moduleData))
+	return moduleData.Version
+}
+
+func TestGitDownloadModule(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is needed to clone local repositories")
+	}
+	dir, err := ioutil.TempDir("", "wio-toolchain")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mirror, toolchains := filepath.Join(dir, "mirror"), filepath.Join(dir, "toolchain")
+	os.Setenv("WIOTOOLCHAINMIRROR", mirror)
+	defer os.Unsetenv("WIOTOOLCHAINMIRROR")
+	older, newer := createToolchainRepo(t, mirror)
+
+	// the locked commit is checked out even though master moved on
+	lock := &types.LockImpl{}
+	lock.SetToolchainVersion(gitToolchainUrl, DefaultRef, older)
+	path, err := GitDownloader{Lock: lock}.DownloadModule(toolchains, gitToolchainUrl, "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, ToolchainClonePath(toolchains, gitToolchainUrl, older), path)
+	assert.Equal(t, "1.0.0", toolchainVersion(t, path))
+	assert.Equal(t, older, lock.ToolchainVersion(gitToolchainUrl, DefaultRef))
+
+	// retooling locks the newest commit and keeps the folder of the older one for other projects
+	retooled := &types.LockImpl{}
+	retooled.SetToolchainVersion(gitToolchainUrl, DefaultRef, older)
+	path, err = GitDownloader{Lock: retooled}.DownloadModule(toolchains, gitToolchainUrl, "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, ToolchainClonePath(toolchains, gitToolchainUrl, newer), path)
+	assert.Equal(t, "1.1.0", toolchainVersion(t, path))
+	assert.Equal(t, newer, retooled.ToolchainVersion(gitToolchainUrl, DefaultRef))
+	assert.Equal(t, "1.0.0", toolchainVersion(t, ToolchainClonePath(toolchains, gitToolchainUrl, older)))
+
+	// toolchains that are already there are not downloaded again
+	path, err = GitDownloader{Lock: lock}.DownloadModule(toolchains, gitToolchainUrl, "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, ToolchainClonePath(toolchains, gitToolchainUrl, older), path)
+
+	// nothing is left behind when the locked commit does not exist
+	missing := &types.LockImpl{}
+	missing.SetToolchainVersion(gitToolchainUrl, DefaultRef, "0123456789012345678901234567890123456789")
+	_, err = GitDownloader{Lock: missing}.DownloadModule(toolchains, gitToolchainUrl, "", false)
+	assert.Error(t, err)
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+}
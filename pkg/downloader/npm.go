@@ -2,15 +2,22 @@ package downloader
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"wio/internal/config/root"
+	"wio/internal/env"
+	"wio/internal/types"
 	"wio/pkg/log"
+	"wio/pkg/npm/publish"
 	"wio/pkg/npm/resolve"
+	"wio/pkg/npm/semver"
 	"wio/pkg/util"
 	"wio/pkg/util/sys"
 )
 
-type NpmDownloader struct{}
+type NpmDownloader struct {
+	Lock *types.LockImpl
+}
 
 func traverseAndDelete(n *resolve.Node) error {
 	path := sys.Path(root.GetToolchainPath(), sys.WioFolder, sys.Modules, n.Name+"__"+n.ResolvedVersion.String())
@@ -28,6 +35,21 @@ func traverseAndDelete(n *resolve.Node) error {
 	return nil
 }
 
+// Whether a toolchain and all of its dependencies are installed, which is not the case when an
+// earlier install failed part way
+func treeInstalled(n *resolve.Node) bool {
+	path := sys.Path(root.GetToolchainPath(), sys.WioFolder, sys.Modules, n.Name+"__"+n.ResolvedVersion.String())
+	if !sys.Exists(path) {
+		return false
+	}
+	for _, dep := range n.Dependencies {
+		if !treeInstalled(dep) {
+			return false
+		}
+	}
+	return true
+}
+
 func traverseAndSymlink(n *resolve.Node) error {
 	oldPath := sys.Path(root.GetToolchainPath(), sys.WioFolder, sys.Modules, n.Name+"__"+n.ResolvedVersion.String())
 	newFilePath := sys.Path(root.GetToolchainPath(), fmt.Sprintf("%s__%s", n.Name, n.ResolvedVersion.String()))
@@ -51,24 +73,53 @@ func traverseAndSymlink(n *resolve.Node) error {
 	return nil
 }
 
-func (npmDownloaded NpmDownloader) DownloadModule(path, name, version string, retool bool) (string, error) {
+// Downloads a toolchain from the registry. The version can be a range which resolves to the newest
+// matching version and is locked from then on along with its dependencies until it is retooled
+func (npmDownloader NpmDownloader) DownloadModule(path, name, query string, retool bool) (string, error) {
 	info := resolve.NewInfo(path)
+	if mirror := env.GetToolchainMirror(); mirror != "" {
+		info.SetMirror(mirror)
+	}
+	if util.IsEmptyString(query) {
+		query = resolve.Latest
+	} else if query != resolve.Latest && semver.MakeQuery(query) == nil {
+		return "", util.Error("toolchain %s version %s is not a valid version or range", name, query)
+	}
+
+	if version := npmDownloader.Lock.ToolchainVersion(name, query); version != "" && !retool {
+		toolchainPath := sys.Path(path, name) + "__" + version
+		if toolchainInstalled(toolchainPath, npmDownloader.Lock) {
+			log.Write(log.Cyan, "Using toolchain ")
+			log.Writeln(log.Green, "%s@%s", name, version)
+			warnNewerToolchain(info, name, query, version)
+			return toolchainPath, nil
+		}
+	}
 
 	var err error = nil
-	if util.IsEmptyString(version) {
+	version := query
+	if !retool {
+		// dependencies of the toolchain keep their locked versions too
+		info.SetToolchainLock(npmDownloader.Lock)
+		if locked := npmDownloader.Lock.ToolchainVersion(name, query); locked != "" {
+			version = locked
+		}
+	}
+	if version == resolve.Latest {
 		if version, err = info.GetLatest(name); err != nil {
 			return "", util.Error("no version found for toolchain %s", name)
 		}
 	}
 
-	log.Write(log.Cyan, "Fetching toolchain ")
-	log.Writeln(log.Green, "%s@%s", name, version)
-
 	node := &resolve.Node{Name: name, ConfigVersion: version}
 
 	if err = info.ResolveTree(node, true); err != nil {
 		return "", err
 	}
+	version = node.ResolvedVersion.String()
+
+	log.Write(log.Cyan, "Fetching toolchain ")
+	log.Writeln(log.Green, "%s@%s", name, version)
 
 	pkgPath := sys.Path(root.GetToolchainPath(), sys.WioFolder, sys.Modules, name+"__"+version)
 
@@ -79,7 +130,7 @@ func (npmDownloaded NpmDownloader) DownloadModule(path, name, version string, re
 		}
 
 		shouldDownload = true
-	} else if !sys.Exists(pkgPath) {
+	} else if !treeInstalled(node) {
 		shouldDownload = true
 	}
 
@@ -92,9 +143,84 @@ func (npmDownloaded NpmDownloader) DownloadModule(path, name, version string, re
 		log.Writeln(log.Green, "|> Already Exists!")
 	}
 
+	if err := npmDownloader.verifyIntegrity(node); err != nil {
+		return "", err
+	}
 	if err := traverseAndSymlink(node); err != nil {
 		return "", err
 	}
 
+	npmDownloader.Lock.SetToolchainVersion(name, query, version)
+	npmDownloader.lockDependencies(node)
 	return sys.Path(path, node.Name) + "__" + version, nil
 }
+
+// Records the versions dependencies of a toolchain resolved to by their ranges, which are used
+// for the paths of dependencies in WioConfig.cmake
+func (npmDownloader NpmDownloader) lockDependencies(n *resolve.Node) {
+	for _, dep := range n.Dependencies {
+		npmDownloader.Lock.SetToolchainVersion(dep.Name, dep.ConfigVersion, dep.ResolvedVersion.String())
+		npmDownloader.lockDependencies(dep)
+	}
+}
+
+// Checks tarballs of a toolchain and its dependencies against the integrity in wio.lock, which was
+// recorded when they were first installed. This catches a registry or mirror serving different
+// contents for a version. Toolchains failing the check are removed
+func (npmDownloader NpmDownloader) verifyIntegrity(n *resolve.Node) error {
+	file := n.Name + "__" + n.ResolvedVersion.String()
+	tar := sys.Path(root.GetToolchainPath(), sys.WioFolder, sys.Cache, file+".tgz")
+	if sys.Exists(tar) {
+		tarData, err := ioutil.ReadFile(tar)
+		if err != nil {
+			return err
+		}
+		locked := npmDownloader.Lock.ToolchainIntegrity(n.Name, n.ResolvedVersion.String())
+		if locked == "" {
+			npmDownloader.Lock.SetToolchainIntegrity(n.Name, n.ResolvedVersion.String(), publish.Integrity(tarData))
+		} else if !publish.CheckIntegrity(tarData, locked) {
+			if err := traverseAndDelete(n); err != nil {
+				return err
+			}
+			os.RemoveAll(tar)
+			return util.Error("toolchain %s@%s does not match the integrity in wio.lock", n.Name,
+				n.ResolvedVersion.String())
+		}
+	}
+
+	for _, dep := range n.Dependencies {
+		if err := npmDownloader.verifyIntegrity(dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Warns when a version newer than the locked one matches the range of a toolchain. The registry
+// not being reachable is not a problem since the locked version is installed
+func warnNewerToolchain(info *resolve.Info, name, query, version string) {
+	newest := ""
+	if query == resolve.Latest {
+		latest, err := info.GetLatest(name)
+		if err != nil {
+			log.Verbln("could not check for newer versions of %s: %s", name, err.Error())
+			return
+		}
+		newest = latest
+	} else {
+		list, err := info.GetList(name)
+		if err != nil {
+			log.Verbln("could not check for newer versions of %s: %s", name, err.Error())
+			return
+		}
+		if best := semver.MakeQuery(query).FindBest(list); best != nil {
+			newest = best.String()
+		}
+	}
+
+	locked, found := semver.Parse(version), semver.Parse(newest)
+	if locked != nil && found != nil && found.GT(*locked) {
+		log.Warnln("toolchain %s@%s is locked but %s matches %s, build with --retool to use it", name, version,
+			newest, query)
+	}
+}
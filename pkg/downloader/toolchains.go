@@ -0,0 +1,247 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"wio/internal/config/root"
+	"wio/internal/types"
+	"wio/pkg/npm/resolve"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+)
+
+// Toolchain downloaded into the toolchain directory as name__version or url__commit
+type Toolchain struct {
+	// npm package name or url of the git repository
+	Name string
+	// version or git commit the toolchain was downloaded at
+	Ref string
+	// version in package.json of the toolchain
+	Version string
+	Git     bool
+	// folder of the toolchain, packages from npm are linked from here
+	Path string
+	Size int64
+}
+
+// Folder of the toolchain relative to the toolchain directory
+func (t Toolchain) Folder() string {
+	return t.Name + "__" + t.Ref
+}
+
+// Lists toolchains in the toolchain directory sorted by name and version
+func ListToolchains() ([]Toolchain, error) {
+	return listToolchains(true)
+}
+
+// Lists toolchains, sizes are only computed when asked for since it walks every file
+func listToolchains(sizes bool) ([]Toolchain, error) {
+	toolchainPath := root.GetToolchainPath()
+	var toolchains []Toolchain
+
+	err := filepath.Walk(toolchainPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if path == toolchainPath {
+			return nil
+		}
+		relPath, err := filepath.Rel(toolchainPath, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		// packages from npm are installed in .wio and linked from the toolchain directory
+		if relPath == sys.WioFolder {
+			return filepath.SkipDir
+		}
+
+		split := strings.LastIndex(relPath, "__")
+		isLink := info.Mode()&os.ModeSymlink != 0
+		if split < 0 || (!isLink && !info.IsDir()) {
+			return nil
+		}
+
+		toolchain := Toolchain{Name: relPath[:split], Ref: relPath[split+2:], Git: !isLink, Path: path}
+		realPath := path
+		if isLink {
+			if realPath, err = filepath.EvalSymlinks(path); err != nil {
+				// link to a package that was removed
+				realPath = ""
+			}
+		}
+		if realPath != "" {
+			moduleData := &ModuleData{}
+			if err := sys.NormalIO.ParseJson(sys.Path(realPath, "package.json"), moduleData); err == nil {
+				toolchain.Version = moduleData.Version
+			}
+			if sizes {
+				if toolchain.Size, err = util.DirSize(realPath); err != nil {
+					return err
+				}
+			}
+		}
+		toolchains = append(toolchains, toolchain)
+
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(toolchains, func(i, j int) bool {
+		if toolchains[i].Name != toolchains[j].Name {
+			return toolchains[i].Name < toolchains[j].Name
+		}
+		return toolchains[i].Ref < toolchains[j].Ref
+	})
+	return toolchains, nil
+}
+
+// Removes a toolchain. Packages from npm are removed along with their cached tarball
+func RemoveToolchain(toolchain Toolchain) error {
+	toolchainPath := root.GetToolchainPath()
+	if !toolchain.Git {
+		modulesPath := sys.Path(toolchainPath, sys.WioFolder)
+		if err := os.RemoveAll(sys.Path(modulesPath, sys.Modules, toolchain.Folder())); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(sys.Path(modulesPath, sys.Cache, toolchain.Folder()+".tgz")); err != nil {
+			return err
+		}
+	}
+	if err := os.RemoveAll(toolchain.Path); err != nil {
+		return err
+	}
+
+	// remove folders of the git host and owner that are left empty
+	for dir := filepath.Dir(toolchain.Path); dir != toolchainPath && strings.HasPrefix(dir, toolchainPath); {
+		if empty, err := util.IsEmpty(dir); err != nil || !empty {
+			break
+		}
+		if err := os.Remove(dir); err != nil {
+			return err
+		}
+		dir = filepath.Dir(dir)
+	}
+	return nil
+}
+
+// Toolchains found to be used while pruning
+type toolchainUsage struct {
+	toolchains []Toolchain
+	installed  map[string]Toolchain
+	locks      []*types.LockImpl
+	used       map[string]bool
+}
+
+// Finds installed toolchains that are not used by any of the toolchain links. Toolchains the used
+// ones depend on are kept, at the versions locked in any of the locks
+func UnusedToolchains(links []string, locks []*types.LockImpl) ([]Toolchain, error) {
+	toolchains, err := ListToolchains()
+	if err != nil {
+		return nil, err
+	}
+	usage := &toolchainUsage{
+		toolchains: toolchains,
+		installed:  map[string]Toolchain{},
+		locks:      locks,
+		used:       map[string]bool{},
+	}
+	for _, toolchain := range toolchains {
+		usage.installed[toolchain.Folder()] = toolchain
+	}
+
+	for _, link := range links {
+		name, ref, isGit, err := ParseToolchainLink(link)
+		if err != nil {
+			return nil, err
+		}
+		if ref == "" && isGit {
+			ref = DefaultRef
+		} else if !isGit {
+			// ranges and the latest version resolve to the newest installed version unless a
+			// newer one was published since
+			ref = newestInstalled(toolchains, name, ref)
+		}
+		usage.markUsed(name + "__" + ref)
+	}
+
+	var unused []Toolchain
+	for _, toolchain := range toolchains {
+		if !usage.used[toolchain.Folder()] {
+			unused = append(unused, toolchain)
+		}
+	}
+	return unused, nil
+}
+
+// Newest installed version of a toolchain matching a version or range, all versions match an
+// empty range. The range itself is returned when nothing matches
+func newestInstalled(toolchains []Toolchain, name string, ref string) string {
+	if semver.Parse(ref) != nil {
+		return ref
+	}
+	query := semver.MakeQuery(ref)
+	if ref == "" || ref == resolve.Latest {
+		query = semver.MakeQuery("*")
+	}
+	if query == nil {
+		return ref
+	}
+
+	var versions semver.List
+	for _, toolchain := range toolchains {
+		if version := semver.Parse(toolchain.Ref); toolchain.Name == name && version != nil {
+			versions = append(versions, version)
+		}
+	}
+	versions.Sort()
+	if best := query.FindBest(versions); best != nil {
+		return best.String()
+	}
+	return ref
+}
+
+// Version a toolchain dependency that is not locked uses out of the installed versions
+func installedVersion(name string, query string) string {
+	if semver.Parse(query) != nil {
+		return query
+	}
+	toolchains, err := listToolchains(false)
+	if err != nil {
+		return query
+	}
+	return newestInstalled(toolchains, name, query)
+}
+
+// Marks a toolchain and the toolchains it depends on as used
+func (usage *toolchainUsage) markUsed(folder string) {
+	toolchain, exists := usage.installed[folder]
+	if !exists || usage.used[folder] {
+		return
+	}
+	usage.used[folder] = true
+
+	moduleData := &ModuleData{}
+	if err := sys.NormalIO.ParseJson(sys.Path(toolchain.Path, "package.json"), moduleData); err != nil {
+		return
+	}
+	for name, query := range moduleData.Dependencies {
+		locked := false
+		for _, lock := range usage.locks {
+			if version := lock.ToolchainVersion(name, query); version != "" {
+				usage.markUsed(name + "__" + version)
+				locked = true
+			}
+		}
+		if !locked {
+			usage.markUsed(name + "__" + newestInstalled(usage.toolchains, name, query))
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package downloader
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wio/internal/types"
+	"wio/pkg/npm/resolve"
+	"wio/pkg/npm/semver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseToolchainLink(t *testing.T) {
+	name, ref, isGit, err := ParseToolchainLink("cosa:^1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "wio-framework-avr-cosa", name)
+	assert.Equal(t, "^1.2.0", ref)
+	assert.False(t, isGit)
+
+	name, ref, isGit, err = ParseToolchainLink("github.com/foo/bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "github.com/foo/bar", name)
+	assert.Equal(t, "", ref)
+	assert.True(t, isGit)
+
+	_, _, _, err = ParseToolchainLink("https://github.com/foo/bar")
+	assert.Error(t, err)
+}
+
+func TestNewestInstalled(t *testing.T) {
+	toolchains := []Toolchain{
+		{Name: "avr-gcc", Ref: "1.0.0"},
+		{Name: "avr-gcc", Ref: "1.4.2"},
+		{Name: "avr-gcc", Ref: "2.0.0"},
+		{Name: "other", Ref: "1.9.0"},
+	}
+	assert.Equal(t, "1.4.2", newestInstalled(toolchains, "avr-gcc", "^1.0.0"))
+	assert.Equal(t, "1.0.0", newestInstalled(toolchains, "avr-gcc", "~1.0.0"))
+	assert.Equal(t, "2.0.0", newestInstalled(toolchains, "avr-gcc", ""))
+	assert.Equal(t, "2.0.0", newestInstalled(toolchains, "avr-gcc", "latest"))
+	assert.Equal(t, "1.2.0", newestInstalled(toolchains, "avr-gcc", "1.2.0"))
+	assert.Equal(t, "^3.0.0", newestInstalled(toolchains, "avr-gcc", "^3.0.0"))
+}
+
+func TestLockDependencies(t *testing.T) {
+	node := &resolve.Node{Name: "wio-framework-avr-cosa", ConfigVersion: "^1.0.0",
+		ResolvedVersion: semver.Parse("1.2.0")}
+	gcc := &resolve.Node{Name: "avr-gcc", ConfigVersion: "^5.0.0", ResolvedVersion: semver.Parse("5.4.0")}
+	libc := &resolve.Node{Name: "avr-libc", ConfigVersion: "~2.0.0", ResolvedVersion: semver.Parse("2.0.1")}
+	gcc.Dependencies = []*resolve.Node{libc}
+	node.Dependencies = []*resolve.Node{gcc}
+
+	lock := &types.LockImpl{}
+	NpmDownloader{Lock: lock}.lockDependencies(node)
+	assert.Equal(t, "5.4.0", lock.ToolchainVersion("avr-gcc", "^5.0.0"))
+	assert.Equal(t, "2.0.1", lock.ToolchainVersion("avr-libc", "~2.0.0"))
+	assert.Equal(t, "", lock.ToolchainVersion("wio-framework-avr-cosa", "^1.0.0"))
+	assert.True(t, lock.Changed())
+}
+
+func TestMarkUsed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-toolchains")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	toolchain := func(name string, version string, dependencies map[string]string) Toolchain {
+		path := filepath.Join(dir, name+"__"+version)
+		assert.NoError(t, os.MkdirAll(path, os.ModePerm))
+		data, _ := json.Marshal(ModuleData{Name: name, Version: version, Dependencies: dependencies})
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(path, "package.json"), data, 0644))
+		return Toolchain{Name: name, Ref: version, Version: version, Path: path}
+	}
+	toolchains := []Toolchain{
+		toolchain("wio-framework-avr-cosa", "1.2.0", map[string]string{"avr-gcc": "^5.0.0"}),
+		toolchain("avr-gcc", "5.4.0", nil),
+		toolchain("avr-gcc", "5.9.0", nil),
+	}
+	newUsage := func(locks []*types.LockImpl) *toolchainUsage {
+		usage := &toolchainUsage{toolchains: toolchains, installed: map[string]Toolchain{}, locks: locks,
+			used: map[string]bool{}}
+		for _, t := range toolchains {
+			usage.installed[t.Folder()] = t
+		}
+		return usage
+	}
+
+	// locked dependencies are kept even when a newer version matches
+	lock := &types.LockImpl{}
+	lock.SetToolchainVersion("avr-gcc", "^5.0.0", "5.4.0")
+	usage := newUsage([]*types.LockImpl{lock})
+	usage.markUsed("wio-framework-avr-cosa__1.2.0")
+	assert.Equal(t, map[string]bool{"wio-framework-avr-cosa__1.2.0": true, "avr-gcc__5.4.0": true}, usage.used)
+
+	// without a lock the newest matching version is used
+	usage = newUsage(nil)
+	usage.markUsed("wio-framework-avr-cosa__1.2.0")
+	assert.Equal(t, map[string]bool{"wio-framework-avr-cosa__1.2.0": true, "avr-gcc__5.9.0": true}, usage.used)
+}
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
+	"wio/internal/constants"
 	"wio/pkg/npm"
 	"wio/pkg/npm/registry"
 	"wio/pkg/util"
@@ -58,8 +61,13 @@ func UrlResolve(values ...string) string {
 }
 
 func FetchPackageData(name string) (*npm.Data, error) {
+	return FetchPackageDataFrom(registry.Url(), name)
+}
+
+// Fetches data of a package from a registry other than the configured one
+func FetchPackageDataFrom(registryUrl string, name string) (*npm.Data, error) {
 	var data npm.Data
-	url := UrlResolve(registry.WioPackageRegistry, name)
+	url := UrlResolve(registryUrl, name)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -79,10 +87,38 @@ func FetchPackageData(name string) (*npm.Data, error) {
 	return &data, nil
 }
 
+// Checks if a version of a package is published, packages that were never published do not exist
+func VersionExists(name string, versionStr string) (bool, error) {
+	url := UrlResolve(registry.Url(), name)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/vnd.npm.install-v1+json")
+	resp, err := Npm.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, util.Error("registry GET (%s) returned %d", url, resp.StatusCode)
+	}
+	var data npm.Data
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return false, err
+	}
+	_, exists := data.Versions[versionStr]
+	return exists, nil
+}
+
 func FetchPackageVersion(name string, versionStr string) (*npm.Version, error) {
 	// assumes `versionStr` is a hard version
 	var version npm.Version
-	url := UrlResolve(registry.WioPackageRegistry, name, versionStr)
+	url := UrlResolve(registry.Url(), name, versionStr)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -100,6 +136,39 @@ func FetchPackageVersion(name string, versionStr string) (*npm.Version, error) {
 	return &version, nil
 }
 
+// Searches the registry for wio packages matching the terms. Only packages with the wio keyword are
+// returned since registries like npm also have packages that are not for wio
+func Search(terms []string, size int) ([]npm.SearchPackage, error) {
+	query := url.Values{}
+	query.Set("text", strings.Join(append(append([]string{}, terms...), "keywords:"+constants.Wio), " "))
+	query.Set("size", strconv.Itoa(size))
+	searchUrl := UrlResolve(registry.Url(), "-", "v1", "search") + "?" + query.Encode()
+
+	req, err := http.NewRequest("GET", searchUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	var result npm.SearchResult
+	status, err := GetJson(Npm, req, &result)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, util.Error("registry GET (%s) returned %d", searchUrl, status)
+	}
+
+	packages := make([]npm.SearchPackage, 0, len(result.Objects))
+	for _, object := range result.Objects {
+		for _, keyword := range object.Package.Keywords {
+			if keyword == constants.Wio {
+				packages = append(packages, object.Package)
+				break
+			}
+		}
+	}
+	return packages, nil
+}
+
 func downloadTarball(url string, dest string) error {
 	if !strings.HasSuffix(url, ".tgz") {
 		return util.Error("invalid tarball URL: %s", url)
@@ -1,6 +1,11 @@
 package client
 
-import "testing"
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
 
 func TestFindFirstSlash(t *testing.T) {
 	val1 := findFirstSlash("")
@@ -47,3 +52,35 @@ func TestUrlResolve(t *testing.T) {
 		t.Errorf("TestResolveUrl() -- failed!")
 	}
 }
+
+func TestSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/-/v1/search" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if text := r.URL.Query().Get("text"); text != "uart avr keywords:wio" {
+			t.Errorf("unexpected text %s", text)
+		}
+		w.Write([]byte(`{"objects": [
+			{"package": {"name": "uart", "version": "1.0.0", "keywords": ["wio", "pkg"]}},
+			{"package": {"name": "uart-js", "version": "2.0.0", "keywords": ["serial"]}}
+		], "total": 2}`))
+	}))
+	defer server.Close()
+
+	os.Setenv("WIOREGISTRY", server.URL)
+	defer os.Unsetenv("WIOREGISTRY")
+
+	// the terms of the caller are left as they are even when they have room for more
+	terms := append(make([]string, 0, 3), "uart", "avr")
+	packages, err := Search(terms, 20)
+	if err != nil {
+		t.Fatalf("Search() -- failed: %s", err.Error())
+	}
+	if extra := terms[:3][2]; extra != "" {
+		t.Errorf("Search() -- changed the terms given: %s", extra)
+	}
+	if len(packages) != 1 || packages[0].Name != "uart" || packages[0].Version != "1.0.0" {
+		t.Errorf("Search() -- expected only uart@1.0.0, got %v", packages)
+	}
+}
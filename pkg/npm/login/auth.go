@@ -0,0 +1,109 @@
+package login
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"wio/pkg/log"
+	"wio/pkg/npm/client"
+	"wio/pkg/util"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Header npm sends the one-time password of accounts with two-factor authentication in
+const OtpHeader = "npm-otp"
+
+// One-time password sent with registry requests. Commands set it from --otp, otherwise it is
+// asked for when the registry requires one
+var OneTimePassword string
+
+// Sends a request made by newRequest with the one-time password. When the registry asks for a
+// one-time password, it is read from the terminal and the request is sent again
+func Send(newRequest func() (*http.Request, error)) (*http.Response, error) {
+	for tries := 0; ; tries++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		if OneTimePassword != "" {
+			req.Header.Set(OtpHeader, OneTimePassword)
+		}
+		resp, err := client.Npm.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if tries > 0 || !isOtpChallenge(resp) {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		if OneTimePassword, err = promptOtp(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Registries answer 401 with www-authenticate: OTP when a one-time password is missing or wrong
+func isOtpChallenge(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	for _, value := range resp.Header["Www-Authenticate"] {
+		for _, scheme := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(scheme), "otp") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func promptOtp() (string, error) {
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return "", util.Error("registry requires a one-time password, pass it with --otp")
+	}
+	log.Info(log.Cyan, "One-time password: ")
+	code, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if code = strings.TrimSpace(code); code == "" {
+		return "", util.Error("one-time password is required")
+	}
+	return code, nil
+}
+
+// Returns the name of the user a token belongs to
+func Whoami(registryUrl string, token string) (string, error) {
+	url := client.UrlResolve(registryUrl, "-", "whoami")
+	log.Verbln("GET %s", url)
+	resp, err := Send(func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("authorization", "Bearer "+token)
+		req.Header.Set("accept", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return "", util.Error("token is not valid for %s", registryUrl)
+	} else if resp.StatusCode != http.StatusOK {
+		return "", util.Error("registry GET %s returned %d", url, resp.StatusCode)
+	}
+	res := &struct {
+		Username string `json:"username"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return "", err
+	}
+	return res.Username, nil
+}
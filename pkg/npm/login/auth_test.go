@@ -0,0 +1,39 @@
+package login
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhoami(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/-/whoami" || r.Header.Get("authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(OtpHeader) != "123456" {
+			w.Header().Set("www-authenticate", "OTP")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"username": "waterloop"}`))
+	}))
+	defer server.Close()
+	defer func() { OneTimePassword = "" }()
+
+	OneTimePassword = "123456"
+	name, err := Whoami(server.URL, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "waterloop", name)
+
+	_, err = Whoami(server.URL, "wrong")
+	assert.Error(t, err)
+
+	// the password cannot be asked for without a terminal
+	OneTimePassword = ""
+	_, err = Whoami(server.URL, "secret")
+	assert.EqualError(t, err, "registry requires a one-time password, pass it with --otp")
+}
@@ -4,35 +4,35 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
-	"wio/internal/config/root"
 	"wio/pkg/log"
 	"wio/pkg/npm/client"
-	"wio/pkg/npm/registry"
 	"wio/pkg/util"
-	"wio/pkg/util/sys"
 )
 
 const (
 	TokensFileName = "tokens.json"
 )
 
-func Do(name, pass, email string) (*Response, error) {
+func Do(name, pass, email, registryUrl string) (*Response, error) {
 	header := ReqHeader()
 	body := ReqBody(name, pass, email)
-	req, err := Request(header, body)
+	resp, err := Send(func() (*http.Request, error) {
+		return Request(header, body, registryUrl)
+	})
 	if err != nil {
 		return nil, err
 	}
-	res := &Response{}
-	status, err := client.GetJson(client.Npm, req, res)
-	if err != nil {
-		return nil, err
-	}
-	if status == http.StatusUnauthorized {
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
 		return nil, util.Error("401 invalid npm login")
 	}
-	if status != http.StatusCreated {
-		return nil, util.Error("%d login error", status)
+	if resp.StatusCode != http.StatusCreated {
+		return nil, util.Error("%d login error", resp.StatusCode)
+	}
+	res := &Response{}
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return nil, err
 	}
 	str, _ := json.MarshalIndent(res, "", Indent)
 	log.Verbln("Response:\n%s", str)
@@ -42,8 +42,8 @@ func Do(name, pass, email string) (*Response, error) {
 	return res, nil
 }
 
-func Request(header *Header, body *Body) (*http.Request, error) {
-	url := client.UrlResolve(registry.WioPackageRegistry, "-", "user", body.Id)
+func Request(header *Header, body *Body, registryUrl string) (*http.Request, error) {
+	url := client.UrlResolve(registryUrl, "-", "user", body.Id)
 	log.Verbln("\nPUT %s", url)
 	str, _ := json.MarshalIndent(body, "", Indent)
 	log.Verbln("Body:\n%s", str)
@@ -57,19 +57,16 @@ func Request(header *Header, body *Body) (*http.Request, error) {
 	return req, nil
 }
 
+// Logs in and adds the token of the user to the saved tokens
 func GetToken(name, pass, email, registry string) (*Tokens, error) {
-	res, err := Do(name, pass, email)
+	res, err := Do(name, pass, email, registry)
 	if err != nil {
 		return nil, err
 	}
-
-	tokensFile := sys.Path(root.GetSecurityPath(), TokensFileName)
-	tokens := &Tokens{}
-
-	if err := sys.NormalIO.ParseJson(tokensFile, tokens); err != nil {
-		tokens.Values = map[string]string{}
+	tokens, err := ReadTokens()
+	if err != nil {
+		return nil, err
 	}
 	tokens.Values[registry] = res.Token
-
-	return tokens, sys.NormalIO.WriteJson(tokensFile, tokens)
+	return tokens, nil
 }
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 	"wio/internal/config/root"
+	"wio/internal/env"
 	"wio/pkg/util/sys"
 )
 
@@ -63,31 +64,55 @@ func ReqBody(user, pass, email string) *Body {
 	}
 }
 
+// Reads saved tokens. There are no tokens when nobody has logged in yet
+func ReadTokens() (*Tokens, error) {
+	tokens := &Tokens{Values: map[string]string{}}
+	path := sys.Path(root.GetSecurityPath(), TokensFileName)
+	if !sys.Exists(path) {
+		return tokens, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, tokens); err != nil {
+		return nil, err
+	}
+	if tokens.Values == nil {
+		tokens.Values = map[string]string{}
+	}
+	return tokens, nil
+}
+
+// Writes tokens so that only the user can read them
 func (t *Tokens) Save() error {
 	path := sys.Path(root.GetSecurityPath())
-	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return err
+	}
+	// the security directory used to be created with open permissions
+	if err := os.Chmod(path, 0700); err != nil {
 		return err
 	}
 	path = sys.Path(path, TokensFileName)
 	str, _ := json.Marshal(t)
-	return ioutil.WriteFile(path, []byte(str), os.ModePerm)
+	if err := ioutil.WriteFile(path, []byte(str), 0600); err != nil {
+		return err
+	}
+	// files written before had open permissions which WriteFile keeps
+	return os.Chmod(path, 0600)
 }
 
+// Token for a registry. WIO_TOKEN is used when it is set, which lets CI publish without logging in
 func LoadToken(registry string) (string, error) {
-	path := sys.Path(root.GetSecurityPath(), TokensFileName)
-	if !sys.Exists(path) {
-		return "", errors.New("not logged in")
+	if token := env.GetToken(); token != "" {
+		return token, nil
 	}
-	data, err := ioutil.ReadFile(path)
+	tokens, err := ReadTokens()
 	if err != nil {
 		return "", err
 	}
-	ret := &Tokens{}
-	if err := json.Unmarshal(data, ret); err != nil {
-		return "", err
-	}
-
-	if value, exists := ret.Values[registry]; exists {
+	if value, exists := tokens.Values[registry]; exists {
 		return value, nil
 	} else {
 		return "", errors.New("not logged in")
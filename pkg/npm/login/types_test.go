@@ -0,0 +1,49 @@
+package login
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"wio/internal/config/root"
+	"wio/pkg/util/sys"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokensSave(t *testing.T) {
+	if sys.GetOS() == sys.WINDOWS {
+		t.Skip("file permissions are not used on windows")
+	}
+	home, err := ioutil.TempDir("", "wio-home")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	oldHome, oldCache := os.Getenv("HOME"), homedir.DisableCache
+	os.Setenv("HOME", home)
+	homedir.DisableCache = true
+	defer func() {
+		os.Setenv("HOME", oldHome)
+		homedir.DisableCache = oldCache
+	}()
+	assert.NoError(t, root.CreateWioRoot())
+
+	// permissions of a directory and file created by older versions are fixed
+	assert.NoError(t, os.Chmod(root.GetSecurityPath(), 0777))
+	path := sys.Path(root.GetSecurityPath(), TokensFileName)
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{}"), 0666))
+	assert.NoError(t, os.Chmod(path, 0666))
+
+	tokens := &Tokens{Values: map[string]string{"https://registry.example.com": "secret"}}
+	assert.NoError(t, tokens.Save())
+
+	info, err := os.Stat(root.GetSecurityPath())
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	info, err = os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	read, err := ReadTokens()
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", read.Values["https://registry.example.com"])
+}
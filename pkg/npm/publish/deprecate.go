@@ -0,0 +1,135 @@
+package publish
+
+import (
+	"sort"
+	"wio/pkg/npm/client"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+)
+
+// Full registry document of a package. It is kept as generic json so that fields wio does not
+// know about are sent back unchanged
+type packument map[string]interface{}
+
+func (p packument) object(key string) map[string]interface{} {
+	if value, ok := p[key].(map[string]interface{}); ok {
+		return value
+	}
+	value := map[string]interface{}{}
+	p[key] = value
+	return value
+}
+
+func fetchPackument(registryUrl, name string) (packument, error) {
+	doc := packument{}
+	url := client.UrlResolve(registryUrl, name) + "?write=true"
+	if err := registryRequest("GET", url, registryUrl, nil, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Marks versions of a package matching the query as deprecated with a message shown to everyone
+// installing them. An empty message removes the deprecation. Versions changed are returned
+func Deprecate(registryUrl, name, versionQuery, message string) ([]string, error) {
+	query := semver.MakeQuery(versionQuery)
+	if query == nil {
+		return nil, util.Error("invalid version expression %s", versionQuery)
+	}
+	doc, err := fetchPackument(registryUrl, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for version, data := range doc.object("versions") {
+		parsed := semver.Parse(version)
+		versionData, ok := data.(map[string]interface{})
+		if parsed == nil || !ok || !query.Matches(parsed) {
+			continue
+		} else if len(parsed.Pre) > 0 && version != versionQuery {
+			// prereleases are only deprecated when they are asked for by version
+			continue
+		}
+		if message == "" {
+			delete(versionData, "deprecated")
+		} else {
+			versionData["deprecated"] = message
+		}
+		changed = append(changed, version)
+	}
+	if len(changed) <= 0 {
+		return nil, util.Error("%s has no versions matching %s", name, versionQuery)
+	}
+	sort.Strings(changed)
+
+	url := client.UrlResolve(registryUrl, name)
+	return changed, registryRequest("PUT", url, registryUrl, doc, nil)
+}
+
+// Removes a version of a package from the registry along with its tarball. Dist-tags pointing
+// to the version are removed and latest moves to the highest version left. Removing the only
+// version removes the package
+func Unpublish(registryUrl, name, version string) error {
+	doc, err := fetchPackument(registryUrl, name)
+	if err != nil {
+		return err
+	}
+	versions := doc.object("versions")
+	versionData, exists := versions[version].(map[string]interface{})
+	if !exists {
+		return util.Error("%s@%s is not published", name, version)
+	}
+	rev, _ := doc["_rev"].(string)
+
+	if len(versions) == 1 {
+		url := client.UrlResolve(registryUrl, name, "-rev", rev)
+		return registryRequest("DELETE", url, registryUrl, nil, nil)
+	}
+
+	delete(versions, version)
+	delete(doc.object("time"), version)
+	tags := doc.object("dist-tags")
+	for tag, tagged := range tags {
+		if tagged == version {
+			delete(tags, tag)
+		}
+	}
+	if _, exists := tags["latest"]; !exists {
+		// prereleases only become latest when there are no releases
+		var releases, prereleases semver.List
+		for other := range versions {
+			if parsed := semver.Parse(other); parsed == nil {
+				continue
+			} else if len(parsed.Pre) > 0 {
+				prereleases = append(prereleases, parsed)
+			} else {
+				releases = append(releases, parsed)
+			}
+		}
+		if len(releases) <= 0 {
+			releases = prereleases
+		}
+		releases.Sort()
+		if len(releases) > 0 {
+			tags["latest"] = releases.Last().String()
+		}
+	}
+
+	url := client.UrlResolve(registryUrl, name, "-rev", rev)
+	if err := registryRequest("PUT", url, registryUrl, doc, nil); err != nil {
+		return err
+	}
+
+	// the tarball is deleted with the revision the document has after the version is removed
+	dist, _ := versionData["dist"].(map[string]interface{})
+	tarball, _ := dist["tarball"].(string)
+	if tarball == "" {
+		return nil
+	}
+	if doc, err = fetchPackument(registryUrl, name); err != nil {
+		return err
+	}
+	rev, _ = doc["_rev"].(string)
+	return registryRequest("DELETE", client.UrlResolve(tarball, "-rev", rev), registryUrl, nil, nil)
+}
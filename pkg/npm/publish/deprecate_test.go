@@ -0,0 +1,103 @@
+package publish
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func versionDoc(versions ...string) string {
+	doc := `{"name": "pkg", "readme": "kept as is", "dist-tags": {"latest": "2.0.0", "beta": "2.0.0-beta.1"},
+		"time": {"1.0.0": "2019-01-01", "2.0.0": "2019-03-01"}, "versions": {`
+	for i, version := range versions {
+		if i > 0 {
+			doc += ", "
+		}
+		doc += `"` + version + `": {"version": "` + version + `", "dist": {"tarball": "REGISTRY/pkg/-/pkg-` +
+			version + `.tgz"}}`
+	}
+	return doc + "}}"
+}
+
+func deprecated(doc packument) map[string]interface{} {
+	messages := map[string]interface{}{}
+	for version, data := range doc.object("versions") {
+		if message, exists := data.(map[string]interface{})["deprecated"]; exists {
+			messages[version] = message
+		}
+	}
+	return messages
+}
+
+func TestDeprecate(t *testing.T) {
+	defer loginTestRegistry()()
+	registry, server := newFakeRegistry(t, "pkg", versionDoc("1.0.0", "1.1.0", "2.0.0-beta.1", "2.0.0"))
+	defer server.Close()
+
+	changed, err := Deprecate(server.URL, "pkg", "^1.0.0", "use 2.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0", "1.1.0"}, changed)
+	assert.Equal(t, map[string]interface{}{"1.0.0": "use 2.0.0", "1.1.0": "use 2.0.0"}, deprecated(registry.doc))
+	assert.Equal(t, "kept as is", registry.doc["readme"])
+	assert.Equal(t, "2-abc", registry.doc["_rev"])
+
+	// prereleases are only deprecated by their exact version
+	changed, err = Deprecate(server.URL, "pkg", ">=1.5.0", "broken")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2.0.0"}, changed)
+	changed, err = Deprecate(server.URL, "pkg", "2.0.0-beta.1", "broken")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2.0.0-beta.1"}, changed)
+
+	// an empty message removes the deprecation
+	_, err = Deprecate(server.URL, "pkg", "*", "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"2.0.0-beta.1": "broken"}, deprecated(registry.doc))
+
+	_, err = Deprecate(server.URL, "pkg", "^3.0.0", "message")
+	assert.EqualError(t, err, "pkg has no versions matching ^3.0.0")
+	_, err = Deprecate(server.URL, "pkg", "not a version", "message")
+	assert.EqualError(t, err, "invalid version expression not a version")
+}
+
+func TestUnpublish(t *testing.T) {
+	defer loginTestRegistry()()
+	registry, server := newFakeRegistry(t, "pkg", versionDoc("1.0.0", "1.1.0", "2.0.0-beta.1", "2.0.0"))
+	defer server.Close()
+
+	// latest moves to the highest release left and tags of the version are removed
+	assert.NoError(t, Unpublish(server.URL, "pkg", "2.0.0"))
+	assert.Equal(t, map[string]interface{}{"latest": "1.1.0", "beta": "2.0.0-beta.1"}, registry.doc["dist-tags"])
+	assert.NotContains(t, registry.doc.object("versions"), "2.0.0")
+	assert.Equal(t, map[string]interface{}{"1.0.0": "2019-01-01"}, registry.doc["time"])
+	assert.Equal(t, []string{"pkg-2.0.0.tgz"}, registry.deleted)
+	assert.Equal(t, "3-abc", registry.doc["_rev"])
+
+	assert.NoError(t, Unpublish(server.URL, "pkg", "2.0.0-beta.1"))
+	assert.Equal(t, map[string]interface{}{"latest": "1.1.0"}, registry.doc["dist-tags"])
+
+	assert.EqualError(t, Unpublish(server.URL, "pkg", "3.0.0"), "pkg@3.0.0 is not published")
+}
+
+func TestUnpublishPrerelease(t *testing.T) {
+	defer loginTestRegistry()()
+	registry, server := newFakeRegistry(t, "pkg", versionDoc("1.0.0", "2.0.0-beta.1"))
+	defer server.Close()
+	registry.doc.object("dist-tags")["latest"] = "1.0.0"
+
+	// prereleases become latest when no release is left
+	assert.NoError(t, Unpublish(server.URL, "pkg", "1.0.0"))
+	assert.Equal(t, map[string]interface{}{"latest": "2.0.0-beta.1", "beta": "2.0.0-beta.1"}, registry.doc["dist-tags"])
+
+	// removing the only version removes the package
+	assert.NoError(t, Unpublish(server.URL, "pkg", "2.0.0-beta.1"))
+	assert.Nil(t, registry.doc)
+}
+
+func TestUnpublishLoggedOut(t *testing.T) {
+	registry, server := newFakeRegistry(t, "pkg", versionDoc("1.0.0"))
+	defer server.Close()
+
+	assert.Error(t, Unpublish(server.URL, "pkg", "1.0.0"))
+	assert.NotNil(t, registry.doc)
+}
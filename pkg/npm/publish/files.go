@@ -0,0 +1,107 @@
+package publish
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"wio/pkg/npm"
+	"wio/pkg/util/sys"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/format/gitignore"
+)
+
+// File with patterns of files to leave out of the package, written like .gitignore
+const IgnoreFile = ".wioignore"
+
+// files that are part of the package even if they are ignored or not in files
+var alwaysIncluded = regexp.MustCompile(`(?i)^(wio\.yml|readme(\..*)?|licen[cs]e(\..*)?)$`)
+
+// Selects files of a project that go in its package. Patterns in ignore_files and .wioignore
+// follow .gitignore rules and leave files out. When files is given, only files matching it are
+// selected. Dotfiles are always left out while wio.yml, README and LICENSE are always kept.
+// Paths are relative to dir and use forward slashes
+func SelectFiles(dir string, data *npm.Version) ([]string, error) {
+	patterns := []gitignore.Pattern{
+		gitignore.ParsePattern(".*", nil),
+		// tarballs left in the project by wio pack, including ones of earlier versions
+		gitignore.ParsePattern(fmt.Sprintf("/%s-[0-9]*.tgz", data.Name), nil),
+	}
+	wioIgnore, err := readIgnoreFile(sys.Path(dir, IgnoreFile))
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range append(append([]string{}, data.IgnorePaths...), wioIgnore...) {
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+	ignored := gitignore.NewMatcher(patterns)
+
+	var allowed gitignore.Matcher
+	if len(data.Files) > 0 {
+		var filePatterns []gitignore.Pattern
+		for _, line := range data.Files {
+			filePatterns = append(filePatterns, gitignore.ParsePattern(line, nil))
+		}
+		allowed = gitignore.NewMatcher(filePatterns)
+	}
+
+	var files []string
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if path == dir {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		parts := strings.Split(relPath, "/")
+
+		if len(parts) == 1 && !info.IsDir() && alwaysIncluded.MatchString(relPath) {
+			files = append(files, relPath)
+			return nil
+		}
+		if ignored.Match(parts, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || (allowed != nil && !allowed.Match(parts, false)) {
+			return nil
+		}
+		files = append(files, relPath)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Reads patterns from an ignore file skipping blank lines and comments
+func readIgnoreFile(path string) ([]string, error) {
+	if !sys.Exists(path) {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,65 @@
+package publish
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wio/pkg/npm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, dir string, files ...string) {
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(file), os.ModePerm))
+	}
+}
+
+func TestSelectFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-publish")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFiles(t, dir, "wio.yml", "README.md", "LICENSE", ".gitignore", ".wio/build/a.o",
+		"include/a.h", "src/a.cpp", "src/a.o", "src/keep.o", "build/a.o", "pkg-1.0.0.tgz")
+	ignore := "# objects\n**/*.o\n!src/keep.o\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, IgnoreFile), []byte(ignore), os.ModePerm))
+
+	files, err := SelectFiles(dir, &npm.Version{Name: "pkg", Version: "1.0.0", IgnorePaths: []string{"/build"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"LICENSE", "README.md", "include/a.h", "src/a.cpp", "src/keep.o", "wio.yml"}, files)
+}
+
+func TestSelectFiles_StaleTarball(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-publish")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// tarballs packed before a version bump are not published with the next version
+	writeFiles(t, dir, "wio.yml", "pkg-0.9.0.tgz", "pkg-1.0.0.tgz", "pkg-extra.tgz", "other-1.0.0.tgz")
+
+	files, err := SelectFiles(dir, &npm.Version{Name: "pkg", Version: "1.1.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"other-1.0.0.tgz", "pkg-extra.tgz", "wio.yml"}, files)
+}
+
+func TestSelectFiles_Allowlist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-publish")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFiles(t, dir, "wio.yml", "README.md", "include/a.h", "src/a.cpp", "tests/main.cpp", "docs/a.md")
+
+	data := &npm.Version{
+		Name:        "pkg",
+		Version:     "1.0.0",
+		Files:       []string{"include", "src/*.cpp"},
+		IgnorePaths: []string{"src/a.cpp"},
+	}
+	files, err := SelectFiles(dir, data)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "include/a.h", "wio.yml"}, files)
+}
@@ -0,0 +1,220 @@
+package publish
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/npm/client"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+)
+
+// registry lookup of dependencies, replaced in tests
+var fetchPackageData = client.FetchPackageData
+
+var (
+	definitionName  = regexp.MustCompile(`^(-D)?[a-zA-Z_][a-zA-Z0-9_]*$`)
+	definitionValue = regexp.MustCompile(`^(-D)?[a-zA-Z_][a-zA-Z0-9_]*(=.*)?$`)
+	placeholder     = regexp.MustCompile(`^\$\(([a-zA-Z_-][a-zA-Z0-9_]*)\)$`)
+	sourceFile      = regexp.MustCompile(`\.(c|cc|cpp)$`)
+)
+
+// Checks that a package works for the projects that depend on it. The files that would be
+// published are checked against the layout dependencies are built with, definitions have to be
+// fillable and dependencies have to resolve from the registry. Problems are returned as messages
+func Lint(dir string, cfg types.Config) ([]string, error) {
+	data, err := VersionData(dir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	files, err := SelectFiles(dir, data)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := lintLayout(cfg, files)
+	problems = append(problems, lintDefinitions(cfg)...)
+	return append(problems, lintDependencies(cfg)...), nil
+}
+
+// Lints a package and prints the problems found. An error is returned when there are any
+func RunLint(dir string, cfg types.Config) error {
+	log.Info(log.Cyan, "Linting package .... ")
+	problems, err := Lint(dir, cfg)
+	if err != nil {
+		log.WriteFailure()
+		return err
+	}
+	if len(problems) > 0 {
+		log.WriteFailure()
+		for _, problem := range problems {
+			log.Errln("%s", problem)
+		}
+		return util.Error("package has %d problem(s)", len(problems))
+	}
+	log.WriteSuccess()
+	return nil
+}
+
+// Dependencies are built from headers in include/ and, unless they are header only, sources in src/
+func lintLayout(cfg types.Config, files []string) []string {
+	var headers, sources, other []string
+	for _, file := range files {
+		switch {
+		case strings.HasPrefix(file, "include/"):
+			headers = append(headers, file)
+		case strings.HasPrefix(file, "src/") && sourceFile.MatchString(path.Base(file)):
+			sources = append(sources, file)
+		case strings.HasPrefix(file, "src/"):
+			other = append(other, file)
+		}
+	}
+
+	var problems []string
+	if len(headers) <= 0 {
+		problems = append(problems, "package has no headers in include/, which is the only directory "+
+			"users can include from")
+	}
+	if cfg.GetInfo().GetOptions().GetIsHeaderOnly() {
+		if len(sources)+len(other) > 0 {
+			problems = append(problems, "package is header only but has files in src/ which are never built")
+		}
+	} else if len(sources) <= 0 {
+		problems = append(problems, "package is not header only but has no .c, .cc or .cpp sources in src/")
+	}
+	return problems
+}
+
+// Definitions a package declares are filled by its users, so they have to be names, and every
+// placeholder the package passes to its dependencies has to be one of them
+func lintDefinitions(cfg types.Config) []string {
+	var problems []string
+	definitions := cfg.GetInfo().GetDefinitions()
+
+	declared := map[string]string{}
+	blocks := []struct {
+		name string
+		set  types.DefinitionSet
+	}{
+		{"global", definitions.GetGlobal()},
+		{"required", definitions.GetRequired()},
+		{"optional", definitions.GetOptional()},
+	}
+	for _, block := range blocks {
+		for _, definition := range append(block.set.GetPrivate(), block.set.GetPublic()...) {
+			if !definitionName.MatchString(definition) {
+				problems = append(problems, fmt.Sprintf("%s definition %s has to be a name without a value",
+					block.name, definition))
+				continue
+			}
+			name := strings.TrimPrefix(definition, "-D")
+			if other, exists := declared[name]; exists && other != block.name {
+				problems = append(problems, fmt.Sprintf("definition %s is both %s and %s", name, other,
+					block.name))
+			}
+			declared[name] = block.name
+		}
+	}
+
+	ingest := definitions.GetIngest()
+	for _, definition := range append(ingest.GetPrivate(), ingest.GetPublic()...) {
+		if !definitionValue.MatchString(definition) {
+			problems = append(problems, fmt.Sprintf("ingest definition %s is not NAME or NAME=VALUE", definition))
+			continue
+		}
+		declared[strings.SplitN(strings.TrimPrefix(definition, "-D"), "=", 2)[0]] = "ingest"
+	}
+
+	if definitions.IsSingleton() {
+		required, optional := definitions.GetRequired(), definitions.GetOptional()
+		count := len(required.GetPrivate()) + len(required.GetPublic()) + len(optional.GetPrivate()) +
+			len(optional.GetPublic())
+		if count > 0 {
+			problems = append(problems, "singleton package has required or optional definitions which are "+
+				"never filled")
+		}
+	}
+
+	var names []string
+	for name := range cfg.GetDependencies() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		dep := cfg.GetDependencies()[name]
+		if dep == nil {
+			continue
+		}
+		for _, definition := range dep.GetDefinitions() {
+			match := placeholder.FindStringSubmatch(strings.TrimSpace(definition))
+			if match == nil {
+				continue
+			}
+			if _, exists := declared[strings.TrimPrefix(match[1], "-D")]; !exists {
+				problems = append(problems, fmt.Sprintf("%s dependency definition %s is not filled by any "+
+					"definition of the package", name, definition))
+			}
+		}
+	}
+	return problems
+}
+
+// Users of the package get its dependencies from the registry by version, so each version range
+// has to match a published version. Vendored dependencies are never fetched from the registry
+func lintDependencies(cfg types.Config) []string {
+	var names []string
+	for name := range cfg.GetDependencies() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		dep := cfg.GetDependencies()[name]
+		if dep == nil || dep.IsVendor() {
+			continue
+		}
+		source := ""
+		switch {
+		case dep.GetPath() != "":
+			source = "a path"
+		case dep.GetGit() != "":
+			source = "git"
+		case dep.GetUrl() != nil:
+			source = "a url"
+		}
+		if source != "" {
+			problems = append(problems, fmt.Sprintf("%s dependency is from %s but users of the package get it "+
+				"from the registry", name, source))
+			continue
+		}
+
+		query := semver.MakeQuery(dep.GetVersion())
+		if query == nil {
+			problems = append(problems, fmt.Sprintf("%s dependency version %s is not valid", name,
+				dep.GetVersion()))
+			continue
+		}
+		data, err := fetchPackageData(name)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s dependency cannot be resolved: %s", name, err.Error()))
+			continue
+		}
+		var list semver.List
+		for version := range data.Versions {
+			if parsed := semver.Parse(version); parsed != nil {
+				list = append(list, parsed)
+			}
+		}
+		list.Sort()
+		if query.FindBest(list) == nil {
+			problems = append(problems, fmt.Sprintf("%s dependency has no published version matching %s", name,
+				dep.GetVersion()))
+		}
+	}
+	return problems
+}
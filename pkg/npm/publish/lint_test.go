@@ -0,0 +1,81 @@
+package publish
+
+import (
+	"testing"
+	"wio/internal/types"
+	"wio/pkg/npm"
+	"wio/pkg/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintLayout(t *testing.T) {
+	library := &types.ConfigImpl{Info: &types.InfoImpl{Options: &types.OptionsImpl{}}}
+	headerOnly := &types.ConfigImpl{Info: &types.InfoImpl{Options: &types.OptionsImpl{Header: true}}}
+
+	assert.Empty(t, lintLayout(library, []string{"include/a.h", "src/a.cpp", "wio.yml"}))
+	assert.Empty(t, lintLayout(headerOnly, []string{"include/a.h", "wio.yml"}))
+
+	assert.Equal(t, []string{
+		"package has no headers in include/, which is the only directory users can include from",
+		"package is not header only but has no .c, .cc or .cpp sources in src/",
+	}, lintLayout(library, []string{"src/a.h", "wio.yml"}))
+	assert.Equal(t, []string{
+		"package is header only but has files in src/ which are never built",
+	}, lintLayout(headerOnly, []string{"include/a.h", "src/a.c"}))
+}
+
+func TestLintDefinitions(t *testing.T) {
+	config := &types.ConfigImpl{
+		Info: &types.InfoImpl{
+			Definitions: &types.DefinitionsImpl{
+				Required: &types.DefinitionSetImpl{Private: []string{"-DUSER_NAME", "BUFFER=5"}},
+				Optional: &types.DefinitionSetImpl{Public: []string{"USER_NAME"}},
+				Ingest:   &types.DefinitionSetImpl{Private: []string{"-DINGESTED=1"}},
+			},
+		},
+		Dependencies: map[string]*types.DependencyImpl{
+			"uart": {Definitions: []string{"$(USER_NAME)", "$(INGESTED)", "$optional(UNKNOWN)", "$(UNKNOWN)"}},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"required definition BUFFER=5 has to be a name without a value",
+		"definition USER_NAME is both required and optional",
+		"uart dependency definition $(UNKNOWN) is not filled by any definition of the package",
+	}, lintDefinitions(config))
+}
+
+func TestLintDependencies(t *testing.T) {
+	defer func(fetch func(string) (*npm.Data, error)) { fetchPackageData = fetch }(fetchPackageData)
+	var fetched []string
+	fetchPackageData = func(name string) (*npm.Data, error) {
+		fetched = append(fetched, name)
+		if name == "missing" {
+			return nil, util.Error("not found")
+		}
+		return &npm.Data{Versions: map[string]npm.Version{"1.0.0": {}, "1.2.0": {}, "garbage": {}}}, nil
+	}
+
+	config := &types.ConfigImpl{
+		Dependencies: map[string]*types.DependencyImpl{
+			"fmt":     {Version: "^1.1.0"},
+			"log":     {Version: "^2.0.0"},
+			"missing": {Version: "^1.0.0"},
+			"invalid": {Version: "latest-ish"},
+			"local":   {Version: "^1.0.0", Path: "../local"},
+			"remote":  {Version: "^1.0.0", Git: "https://github.com/wio/remote"},
+			"vendor":  {Version: "^1.0.0", Vendor: true},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"invalid dependency version latest-ish is not valid",
+		"local dependency is from a path but users of the package get it from the registry",
+		"log dependency has no published version matching ^2.0.0",
+		"missing dependency cannot be resolved: not found",
+		"remote dependency is from git but users of the package get it from the registry",
+	}, lintDependencies(config))
+	// vendored dependencies are never looked up in the registry
+	assert.Equal(t, []string{"fmt", "log", "missing"}, fetched)
+}
@@ -0,0 +1,166 @@
+package publish
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/npm"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+)
+
+// File inside of a package tarball
+type PackedFile struct {
+	Path string
+	Size int64
+}
+
+// Package tarball along with what is in it
+type Tarball struct {
+	Data     *npm.Version
+	File     string
+	Path     string
+	Contents []byte
+	Files    []PackedFile
+
+	UnpackedSize int64
+	Shasum       string
+	Integrity    string
+}
+
+// Generates the package of a project and tars it into dst as <name>-<version>.tgz
+func Pack(dir string, cfg types.Config, dst string) (*Tarball, error) {
+	data, err := VersionData(dir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := GeneratePackage(dir, data); err != nil {
+		return nil, err
+	}
+	file := fmt.Sprintf("%s-%s.tgz", data.Name, data.Version)
+	path := sys.Path(dst, file)
+	if err := MakeTar(dir, path); err != nil {
+		return nil, err
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tarball := &Tarball{
+		Data:      data,
+		File:      file,
+		Path:      path,
+		Contents:  contents,
+		Shasum:    Shasum(contents),
+		Integrity: Integrity(contents),
+	}
+	pkg := sys.Path(dir, sys.WioFolder, "package")
+	if err := filepath.Walk(pkg, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(pkg, path)
+		if err != nil {
+			return err
+		}
+		tarball.Files = append(tarball.Files, PackedFile{Path: filepath.ToSlash(relPath), Size: info.Size()})
+		tarball.UnpackedSize += info.Size()
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(tarball.Files, func(i, j int) bool {
+		return tarball.Files[i].Path < tarball.Files[j].Path
+	})
+
+	data.Dist = npm.Dist{
+		Shasum:       tarball.Shasum,
+		Integrity:    tarball.Integrity,
+		FileCount:    len(tarball.Files),
+		UnpackedSize: int(tarball.UnpackedSize),
+	}
+	return tarball, nil
+}
+
+// Subresource integrity of the tarball like npm computes it
+func Integrity(data []byte) string {
+	ret := sha512.Sum512(data)
+	return "sha512-" + base64.StdEncoding.EncodeToString(ret[:])
+}
+
+// Hash functions integrity can use, from weakest to strongest
+var integrityHashes = []struct {
+	name string
+	sum  func(data []byte) []byte
+}{
+	{"sha1", func(data []byte) []byte { ret := sha1.Sum(data); return ret[:] }},
+	{"sha256", func(data []byte) []byte { ret := sha256.Sum256(data); return ret[:] }},
+	{"sha512", func(data []byte) []byte { ret := sha512.Sum512(data); return ret[:] }},
+}
+
+// Checks data against subresource integrity. Only hashes of the strongest algorithm out of sha1,
+// sha256 and sha512 are used so a weaker hash cannot stand in for a stronger one, any of those
+// matching is enough
+func CheckIntegrity(data []byte, integrity string) bool {
+	strongest := -1
+	expected := map[int][]string{}
+	for _, hash := range strings.Fields(integrity) {
+		split := strings.Index(hash, "-")
+		if split < 0 {
+			continue
+		}
+		for i, algorithm := range integrityHashes {
+			if algorithm.name != hash[:split] {
+				continue
+			}
+			// options like ?foo can follow the hash
+			expected[i] = append(expected[i], strings.SplitN(hash[split+1:], "?", 2)[0])
+			if i > strongest {
+				strongest = i
+			}
+		}
+	}
+	if strongest < 0 {
+		return false
+	}
+
+	sum := base64.StdEncoding.EncodeToString(integrityHashes[strongest].sum(data))
+	for _, hash := range expected[strongest] {
+		if hash == sum {
+			return true
+		}
+	}
+	return false
+}
+
+// Prints files in the tarball and its details
+func LogTarball(tarball *Tarball) {
+	log.Infoln(log.Cyan, "Tarball contents")
+	for _, file := range tarball.Files {
+		log.Infoln("%10s  %s", util.FormatSize(file.Size), file.Path)
+	}
+	log.Infoln(log.Cyan, "Tarball details")
+	details := [][2]string{
+		{"name", tarball.Data.Name},
+		{"version", tarball.Data.Version},
+		{"filename", tarball.File},
+		{"package size", util.FormatSize(int64(len(tarball.Contents)))},
+		{"unpacked size", util.FormatSize(tarball.UnpackedSize)},
+		{"shasum", tarball.Shasum},
+		{"integrity", tarball.Integrity},
+		{"total files", fmt.Sprintf("%d", len(tarball.Files))},
+	}
+	for _, detail := range details {
+		log.Infoln("%-15s %s", detail[0]+":", detail[1])
+	}
+}
@@ -0,0 +1,26 @@
+package publish
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	data := []byte("tarball")
+	assert.True(t, CheckIntegrity(data, Integrity(data)))
+	assert.True(t, CheckIntegrity(data, "sha1-AAAA "+Integrity(data)+"?opt"))
+	assert.True(t, CheckIntegrity(data, "sha256-20tNDRy0gL+a7qJTdxwA/r5ifyNnZfo31qVhTweaOqA="))
+
+	assert.True(t, CheckIntegrity(data, "sha512-AAAA "+Integrity(data)))
+
+	// only the strongest algorithm counts, a matching sha1 does not make up for a wrong sha512
+	sha1 := "sha1-4Q9ucGYdFn71FKtubZhgdDjGqMY="
+	assert.True(t, CheckIntegrity(data, sha1))
+	assert.False(t, CheckIntegrity(data, sha1+" sha512-AAAA"))
+	assert.False(t, CheckIntegrity(data, "sha256-AAAA "+sha1))
+
+	assert.False(t, CheckIntegrity([]byte("other"), Integrity(data)))
+	assert.False(t, CheckIntegrity(data, "md5-AAAA"))
+	assert.False(t, CheckIntegrity(data, ""))
+}
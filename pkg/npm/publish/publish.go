@@ -4,59 +4,73 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"wio/internal/types"
 	"wio/pkg/log"
 	"wio/pkg/npm"
 	"wio/pkg/npm/client"
 	"wio/pkg/npm/login"
-	"wio/pkg/npm/registry"
+	"wio/pkg/util"
 	"wio/pkg/util/sys"
 )
 
-func Do(dir, registryProvided string, cfg types.Config) error {
-	log.Info(log.Cyan, "Retrieving token ... ")
-	token, err := login.LoadToken(registryProvided)
-	if err != nil {
-		log.WriteFailure()
-		return err
-	}
-	log.WriteSuccess()
-	header := NewHeader(token)
+// Options of publishing a package
+type Options struct {
+	// everything is done except sending the package to the registry
+	DryRun bool
+	// package is published without being linted and built first
+	SkipLint bool
+	// builds the package before it is published
+	TrialBuild func() error
+	// dist-tag pointed to the published version, latest when it is empty
+	Tag string
+}
 
-	log.Info(log.Cyan, "Zipping package .... ")
-	data, err := VersionData(dir, cfg)
-	if err != nil {
-		log.WriteFailure()
-		return err
+func Do(dir, registryProvided string, cfg types.Config, options Options) error {
+	tag := options.Tag
+	if tag == "" {
+		tag = "latest"
 	}
-	if err := GeneratePackage(dir, data); err != nil {
-		log.WriteFailure()
+	if err := checkTag(tag); err != nil {
 		return err
 	}
-	tarFile := fmt.Sprintf("%s-%s.tgz", data.Name, data.Version)
-	tarPath := sys.Path(dir, sys.WioFolder, tarFile)
-	if err := MakeTar(dir, tarPath); err != nil {
-		log.WriteFailure()
-		return err
+
+	if !options.SkipLint {
+		if err := RunLint(dir, cfg); err != nil {
+			return err
+		}
+		if options.TrialBuild != nil {
+			if err := options.TrialBuild(); err != nil {
+				return util.Error("package failed to build: %s", err.Error())
+			}
+		}
 	}
-	log.WriteSuccess()
 
-	log.Info(log.Cyan, "Computing shasum ... ")
-	tarData, err := ioutil.ReadFile(tarPath)
+	var header *Header
+	if !options.DryRun {
+		log.Info(log.Cyan, "Retrieving token ... ")
+		token, err := login.LoadToken(registryProvided)
+		if err != nil {
+			log.WriteFailure()
+			return err
+		}
+		log.WriteSuccess()
+		header = NewHeader(token)
+	}
+
+	log.Info(log.Cyan, "Zipping package .... ")
+	tarball, err := Pack(dir, cfg, sys.Path(dir, sys.WioFolder))
 	if err != nil {
 		log.WriteFailure()
 		return err
 	}
-	shasum := Shasum(tarData)
-	tarDist := TarEncode(tarData)
 	log.WriteSuccess()
+	data, tarData, tarFile := tarball.Data, tarball.Contents, tarball.File
+
+	tarDist := TarEncode(tarData)
 	log.Verbln("Data length:    %d", len(tarData))
 	log.Verbln("Encoded length: %d", len(tarDist))
-
-	tarUrl := client.UrlResolve(registry.WioPackageRegistry, data.Name, "-", tarFile)
-	data.Dist = npm.Dist{Shasum: shasum, Tarball: tarUrl}
+	data.Dist.Tarball = client.UrlResolve(registryProvided, data.Name, "-", tarFile)
 
 	payload := &Attachment{
 		Type:   "application/octet-stream",
@@ -69,29 +83,49 @@ func Do(dir, registryProvided string, cfg types.Config) error {
 		Description: data.Description,
 		Readme:      data.Readme,
 
-		DistTags:    map[string]string{"latest": data.Version},
+		DistTags:    map[string]string{tag: data.Version},
 		Versions:    map[string]*npm.Version{data.Version: data},
 		Attachments: map[string]*Attachment{tarFile: payload},
 	}
 
 	url := client.UrlResolve(registryProvided, data.Name)
+	if options.DryRun {
+		LogTarball(tarball)
+		// the tarball is already listed above
+		payload.Data = fmt.Sprintf("(%d bytes of base64)", len(tarDist))
+		str, _ := json.MarshalIndent(body, "", login.Indent)
+		log.Infoln(log.Cyan, "PUT %s", url)
+		log.Info("%s\n", str)
+		log.Info(log.Cyan, "Dry run of publishing ")
+		log.Info(log.Green, "%s@%s", data.Name, data.Version)
+		log.Infoln(log.Cyan, ", nothing was sent")
+		return nil
+	}
+
 	log.Verbln("PUT %s", url)
 	str, _ := json.MarshalIndent(header, "", login.Indent)
 	log.Verbln("Header:\n%s", string(str))
 	str, _ = json.MarshalIndent(body, "", login.Indent)
 	log.Verbln("Body:\n%s", string(str))
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(str))
+	log.Info(log.Cyan, "Sending request .... ")
+	resp, err := login.Send(func() (*http.Request, error) {
+		req, err := http.NewRequest("PUT", url, bytes.NewBuffer(str))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("authorization", header.Authorization)
+		req.Header.Set("content-type", header.ContentType)
+		req.Header.Set("npm-session", header.NpmSession)
+		return req, nil
+	})
 	if err != nil {
+		log.WriteFailure()
 		return err
 	}
-	req.Header.Set("authorization", header.Authorization)
-	req.Header.Set("content-type", header.ContentType)
-	req.Header.Set("npm-session", header.NpmSession)
-
-	log.Info(log.Cyan, "Sending request .... ")
+	defer resp.Body.Close()
+	status := resp.StatusCode
 	res := &Response{}
-	status, err := client.GetJson(client.Npm, req, res)
-	if err != nil {
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
 		log.WriteFailure()
 		return err
 	}
@@ -108,6 +142,6 @@ func Do(dir, registryProvided string, cfg types.Config) error {
 	log.Verbln("Response:\n%s", string(str))
 	log.Info(log.Cyan, "Published ")
 	log.Info(log.Green, "%s@%s", data.Name, data.Version)
-	log.Infoln(log.Cyan, "!")
+	log.Infoln(log.Cyan, " with tag %s!", tag)
 	return nil
 }
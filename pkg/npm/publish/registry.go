@@ -0,0 +1,76 @@
+package publish
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"wio/pkg/log"
+	"wio/pkg/npm/login"
+	"wio/pkg/util"
+)
+
+// Sends a request to the registry with the token of the logged in user. body is sent as json
+// when it is not nil and the response is decoded into out when out is not nil
+func registryRequest(method, url, registryUrl string, body interface{}, out interface{}) error {
+	token, err := login.LoadToken(registryUrl)
+	if err != nil {
+		return err
+	}
+	return sendRequest(method, url, token, body, out)
+}
+
+// Reads from the registry, the token is only sent when the user is logged in
+func registryQuery(url, registryUrl string, out interface{}) error {
+	token, _ := login.LoadToken(registryUrl)
+	return sendRequest("GET", url, token, nil, out)
+}
+
+func sendRequest(method, url, token string, body interface{}, out interface{}) error {
+	var data []byte
+	var err error
+	if body != nil {
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	log.Verbln("%s %s", method, url)
+	resp, err := login.Send(func() (*http.Request, error) {
+		var reader io.Reader
+		if data != nil {
+			reader = bytes.NewReader(data)
+		}
+		req, err := http.NewRequest(method, url, reader)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("authorization", "Bearer "+token)
+		}
+		req.Header.Set("content-type", "application/json")
+		req.Header.Set("accept", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// registries explain what went wrong in error or reason
+		failure := &struct {
+			Error  string `json:"error"`
+			Reason string `json:"reason"`
+		}{}
+		json.NewDecoder(resp.Body).Decode(failure)
+		if failure.Reason != "" {
+			failure.Error = failure.Reason
+		}
+		return util.Error("registry %s (%s) returned %d %s", method, url, resp.StatusCode, failure.Error)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
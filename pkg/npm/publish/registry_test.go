@@ -0,0 +1,124 @@
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Registry holding a single package document the way npm registries store them
+type fakeRegistry struct {
+	name     string
+	doc      packument
+	rev      int
+	deleted  []string
+	readAuth []string
+}
+
+// REGISTRY inside of the document is replaced with the url of the registry
+func newFakeRegistry(t *testing.T, name string, doc string) (*fakeRegistry, *httptest.Server) {
+	registry := &fakeRegistry{name: name, doc: packument{}, rev: 1}
+	server := httptest.NewServer(registry)
+	doc = strings.Replace(doc, "REGISTRY", server.URL, -1)
+	assert.NoError(t, json.Unmarshal([]byte(doc), &registry.doc))
+	registry.doc["_rev"] = registry.revision()
+	return registry, server
+}
+
+func (registry *fakeRegistry) revision() string {
+	return fmt.Sprintf("%d-abc", registry.rev)
+}
+
+func (registry *fakeRegistry) save(doc packument) {
+	registry.rev++
+	registry.doc = doc
+	registry.doc["_rev"] = registry.revision()
+}
+
+func (registry *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	write := r.Method != "GET" || r.URL.Query().Get("write") == "true"
+	if write && r.Header.Get("authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	reply := func(status int, value interface{}) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(value)
+	}
+	conflict := func(rev string) bool {
+		if registry.doc == nil || rev != registry.revision() {
+			reply(http.StatusConflict, map[string]string{"error": "conflict"})
+			return true
+		}
+		return false
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	tagsPath := "-/package/" + registry.name + "/dist-tags"
+	switch {
+	case r.Method == "GET" && path == tagsPath:
+		registry.readAuth = append(registry.readAuth, r.Header.Get("authorization"))
+		reply(http.StatusOK, registry.doc.object("dist-tags"))
+
+	case strings.HasPrefix(path, tagsPath+"/"):
+		tag := strings.TrimPrefix(path, tagsPath+"/")
+		doc := registry.doc
+		if r.Method == "PUT" {
+			var version string
+			json.NewDecoder(r.Body).Decode(&version)
+			doc.object("dist-tags")[tag] = version
+		} else {
+			delete(doc.object("dist-tags"), tag)
+		}
+		registry.save(doc)
+		reply(http.StatusOK, map[string]bool{"ok": true})
+
+	case r.Method == "GET" && path == registry.name:
+		if registry.doc == nil {
+			reply(http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		reply(http.StatusOK, registry.doc)
+
+	case r.Method == "PUT" && strings.HasPrefix(path, registry.name):
+		doc := packument{}
+		json.NewDecoder(r.Body).Decode(&doc)
+		rev, _ := doc["_rev"].(string)
+		if strings.HasPrefix(path, registry.name+"/-rev/") {
+			rev = strings.TrimPrefix(path, registry.name+"/-rev/")
+		}
+		if !conflict(rev) {
+			registry.save(doc)
+			reply(http.StatusCreated, map[string]bool{"ok": true})
+		}
+
+	case r.Method == "DELETE" && strings.HasPrefix(path, registry.name+"/-rev/"):
+		if !conflict(strings.TrimPrefix(path, registry.name+"/-rev/")) {
+			registry.doc = nil
+			reply(http.StatusOK, map[string]bool{"ok": true})
+		}
+
+	case r.Method == "DELETE" && strings.HasPrefix(path, registry.name+"/-/"):
+		parts := strings.Split(strings.TrimPrefix(path, registry.name+"/-/"), "/-rev/")
+		if !conflict(parts[1]) {
+			registry.deleted = append(registry.deleted, parts[0])
+			registry.rev++
+			registry.doc["_rev"] = registry.revision()
+			reply(http.StatusOK, map[string]bool{"ok": true})
+		}
+
+	default:
+		reply(http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+}
+
+func loginTestRegistry() func() {
+	os.Setenv("WIO_TOKEN", "secret")
+	return func() { os.Unsetenv("WIO_TOKEN") }
+}
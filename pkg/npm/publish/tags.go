@@ -0,0 +1,40 @@
+package publish
+
+import (
+	"wio/pkg/npm/client"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+)
+
+// Tags cannot look like versions since they can be installed in place of one
+func checkTag(tag string) error {
+	if tag == "" || semver.MakeQuery(tag) != nil {
+		return util.Error("tag %s has to be a name that is not a version range", tag)
+	}
+	return nil
+}
+
+// Lists dist-tags of a package and the versions they point to
+func DistTags(registryUrl, name string) (map[string]string, error) {
+	tags := map[string]string{}
+	url := client.UrlResolve(registryUrl, "-", "package", name, "dist-tags")
+	if err := registryQuery(url, registryUrl, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Points a dist-tag of a package to a version
+func AddDistTag(registryUrl, name, version, tag string) error {
+	if err := checkTag(tag); err != nil {
+		return err
+	}
+	url := client.UrlResolve(registryUrl, "-", "package", name, "dist-tags", tag)
+	return registryRequest("PUT", url, registryUrl, version, nil)
+}
+
+// Removes a dist-tag from a package
+func RemoveDistTag(registryUrl, name, tag string) error {
+	url := client.UrlResolve(registryUrl, "-", "package", name, "dist-tags", tag)
+	return registryRequest("DELETE", url, registryUrl, nil, nil)
+}
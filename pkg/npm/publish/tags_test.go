@@ -0,0 +1,32 @@
+package publish
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistTags(t *testing.T) {
+	registry, server := newFakeRegistry(t, "pkg", `{"dist-tags": {"latest": "1.0.0"}}`)
+	defer server.Close()
+
+	// tags can be listed without logging in
+	tags, err := DistTags(server.URL, "pkg")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"latest": "1.0.0"}, tags)
+	assert.Equal(t, []string{""}, registry.readAuth)
+	assert.Error(t, AddDistTag(server.URL, "pkg", "1.1.0-beta.1", "beta"))
+
+	defer loginTestRegistry()()
+	assert.NoError(t, AddDistTag(server.URL, "pkg", "1.1.0-beta.1", "beta"))
+	tags, err = DistTags(server.URL, "pkg")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"latest": "1.0.0", "beta": "1.1.0-beta.1"}, tags)
+	assert.Equal(t, []string{"", "Bearer secret"}, registry.readAuth)
+
+	assert.NoError(t, RemoveDistTag(server.URL, "pkg", "beta"))
+	assert.Equal(t, map[string]interface{}{"latest": "1.0.0"}, registry.doc["dist-tags"])
+
+	assert.EqualError(t, AddDistTag(server.URL, "pkg", "1.0.0", "^1.0.0"),
+		"tag ^1.0.0 has to be a name that is not a version range")
+}
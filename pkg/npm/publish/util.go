@@ -9,8 +9,6 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
-	"regexp"
-	"strings"
 	"time"
 	"wio/internal/constants"
 	"wio/internal/types"
@@ -87,49 +85,14 @@ func GeneratePackage(dir string, data *npm.Version) error {
 		}
 	}
 
-	var ignorePathsReg []*regexp.Regexp
-	for _, ignorePath := range data.IgnorePaths {
-		isDir, err := sys.IsDir(ignorePath)
-		if err != nil {
-			return err
-		}
-
-		if isDir {
-			ignorePath = sys.Path(ignorePath, "*")
-		}
-
-		ignorePathsReg = append(ignorePathsReg, regexp.MustCompile(
-			strings.Replace(ignorePath, "/", `\/`, -1)))
+	files, err := SelectFiles(dir, data)
+	if err != nil {
+		return err
 	}
-
-	ignorePathsReg = append(ignorePathsReg, regexp.MustCompile(sys.Path(dir, `\.+.+`)))
-
-	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
+	for _, file := range files {
+		if err := sys.Copy(sys.Path(dir, file), sys.Path(pkg, file)); err != nil {
 			return err
-		} else if path == dir {
-			return nil
 		}
-
-		// ignore all the paths specified
-		for _, ignorePathReg := range ignorePathsReg {
-			if ignorePathReg.MatchString(path) {
-				return nil
-			}
-		}
-
-		relPath, err := filepath.Rel(dir, path)
-		if err != nil {
-			return err
-		}
-
-		if err := sys.Copy(path, sys.Path(pkg, relPath)); err != nil {
-			return err
-		}
-
-		return nil
-	}); err != nil {
-		return err
 	}
 
 	return nil
@@ -174,5 +137,6 @@ func VersionData(dir string, cfg types.Config) (*npm.Version, error) {
 		Repository:   info.GetRepository(),
 
 		IgnorePaths: info.GetIgnoreFiles(),
+		Files:       info.GetFiles(),
 	}, nil
 }
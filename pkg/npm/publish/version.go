@@ -0,0 +1,224 @@
+package publish
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+
+	s "github.com/blang/semver"
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Computes the version after a bump. Bump is major, minor, patch, prerelease or an explicit
+// version. Prerelease bumps the last number of the prerelease or starts one on the next patch,
+// named after preid when it is given
+func BumpVersion(current string, bump string, preid string) (string, error) {
+	version := semver.Parse(current)
+	if version == nil {
+		return "", util.Error("current version %s is not valid", current)
+	}
+
+	next := *version
+	switch bump {
+	case "major":
+		// 1.0.0-1 is released as 1.0.0
+		if next.Minor != 0 || next.Patch != 0 || len(next.Pre) <= 0 {
+			next.Major++
+		}
+		next.Minor, next.Patch = 0, 0
+	case "minor":
+		if next.Patch != 0 || len(next.Pre) <= 0 {
+			next.Minor++
+		}
+		next.Patch = 0
+	case "patch":
+		if len(next.Pre) <= 0 {
+			next.Patch++
+		}
+	case "prerelease":
+		pre, err := bumpPrerelease(next.Pre, preid)
+		if err != nil {
+			return "", err
+		}
+		if len(next.Pre) <= 0 {
+			next.Patch++
+		}
+		next.Build = nil
+		next.Pre = pre
+		return next.String(), nil
+	default:
+		explicit := semver.Parse(strings.TrimPrefix(bump, "v"))
+		if explicit == nil {
+			return "", util.Error("%s is not major, minor, patch, prerelease or a valid version", bump)
+		} else if explicit.Equals(*version) {
+			return "", util.Error("version is already %s", current)
+		}
+		return explicit.String(), nil
+	}
+	next.Pre, next.Build = nil, nil
+	return next.String(), nil
+}
+
+func bumpPrerelease(pre []s.PRVersion, preid string) ([]s.PRVersion, error) {
+	if preid != "" {
+		if _, err := s.NewPRVersion(preid); err != nil {
+			return nil, util.Error("prerelease id %s is not valid", preid)
+		}
+	}
+	number := func(n uint64) s.PRVersion {
+		return s.PRVersion{VersionNum: n, IsNum: true}
+	}
+
+	if len(pre) > 0 && (preid == "" || pre[0].VersionStr == preid) {
+		bumped := append([]s.PRVersion{}, pre...)
+		for i := len(bumped) - 1; i >= 0; i-- {
+			if bumped[i].IsNum {
+				bumped[i] = number(bumped[i].VersionNum + 1)
+				return bumped, nil
+			}
+		}
+		return append(bumped, number(0)), nil
+	}
+	if preid != "" {
+		return []s.PRVersion{{VersionStr: preid}, number(0)}, nil
+	}
+	return []s.PRVersion{number(0)}, nil
+}
+
+// Opens the git repository dir is in. Nil is returned when dir is not in a repository
+func OpenRepository(dir string) (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err == git.ErrRepositoryNotExists {
+		return nil, nil
+	}
+	return repo, err
+}
+
+// Checks that a version can be committed and tagged before wio.yml is changed and returns the
+// signature to commit with. Tracked files of the repository cannot have uncommitted changes and the
+// tag cannot exist yet. Untracked files are left out like npm does, which also means ignore rules
+// that go-git does not read, like core.excludesfile, do not matter
+func CheckRepository(repo *git.Repository, tag string) (*object.Signature, error) {
+	if _, err := repo.Tag(tag); err == nil {
+		return nil, util.Error("git tag %s already exists", tag)
+	}
+	signature, err := gitSignature(repo)
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for file, fileStatus := range status {
+		if fileStatus.Staging != git.Untracked || fileStatus.Worktree != git.Untracked {
+			changed = append(changed, file)
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		return nil, util.Error("git working tree has uncommitted changes: %s", strings.Join(changed, ", "))
+	}
+	return signature, nil
+}
+
+// Commits wio.yml of the project in dir with the message and creates an annotated tag for the commit
+func CommitVersion(repo *git.Repository, dir string, message string, tag string, signature *object.Signature) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	relPath, err := repositoryPath(repo, dir)
+	if err != nil {
+		return err
+	}
+	if _, err := worktree.Add(path.Join(relPath, sys.Config)); err != nil {
+		return err
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature})
+	if err != nil {
+		return err
+	}
+	_, err = repo.CreateTag(tag, hash, &git.CreateTagOptions{Tagger: signature, Message: message})
+	return err
+}
+
+// Signature for commits from the git environment variables, the repository config or the global
+// config, in that order
+func gitSignature(repo *git.Repository) (*object.Signature, error) {
+	name, email := os.Getenv("GIT_AUTHOR_NAME"), os.Getenv("GIT_AUTHOR_EMAIL")
+
+	var sections []*config.Section
+	if cfg, err := repo.Config(); err == nil && cfg.Raw != nil {
+		sections = append(sections, cfg.Raw.Section("user"))
+	}
+	if home, err := homedir.Dir(); err == nil {
+		if file, err := os.Open(filepath.Join(home, ".gitconfig")); err == nil {
+			global := config.New()
+			if config.NewDecoder(file).Decode(global) == nil {
+				sections = append(sections, global.Section("user"))
+			}
+			file.Close()
+		}
+	}
+	for _, section := range sections {
+		if name == "" {
+			name = section.Option("name")
+		}
+		if email == "" {
+			email = section.Option("email")
+		}
+	}
+
+	if name == "" || email == "" {
+		return nil, util.Error("git user.name and user.email have to be set to commit the version")
+	}
+	return &object.Signature{Name: name, Email: email, When: time.Now()}, nil
+}
+
+// Tag name for a version of the package in dir. Packages that are not at the root of the
+// repository, like members of a workspace, are tagged as name@vversion so that packages sharing
+// a repository do not share tags
+func VersionTag(repo *git.Repository, dir string, name string, version string) (string, error) {
+	relPath, err := repositoryPath(repo, dir)
+	if err != nil {
+		return "", err
+	}
+	if relPath != "." {
+		return fmt.Sprintf("%s@v%s", name, version), nil
+	}
+	return fmt.Sprintf("v%s", version), nil
+}
+
+// Path of dir relative to the root of the repository with forward slashes
+func repositoryPath(repo *git.Repository, dir string) (string, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	relPath, err := filepath.Rel(worktree.Filesystem.Root(), absDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(relPath), nil
+}
@@ -0,0 +1,210 @@
+package publish
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wio/pkg/util/sys"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestBumpVersion(t *testing.T) {
+	cases := []struct {
+		current string
+		bump    string
+		preid   string
+		next    string
+	}{
+		{"1.2.3", "major", "", "2.0.0"},
+		{"1.2.3", "minor", "", "1.3.0"},
+		{"1.2.3", "patch", "", "1.2.4"},
+		{"1.2.3+build", "patch", "", "1.2.4"},
+		{"2.0.0-1", "major", "", "2.0.0"},
+		{"1.3.0-beta.2", "minor", "", "1.3.0"},
+		{"1.2.4-0", "patch", "", "1.2.4"},
+		{"1.2.3", "prerelease", "", "1.2.4-0"},
+		{"1.2.4-0", "prerelease", "", "1.2.4-1"},
+		{"1.2.3", "prerelease", "beta", "1.2.4-beta.0"},
+		{"1.2.4-beta.0", "prerelease", "beta", "1.2.4-beta.1"},
+		{"1.2.4-beta", "prerelease", "", "1.2.4-beta.0"},
+		{"1.2.4-alpha.3", "prerelease", "beta", "1.2.4-beta.0"},
+		{"1.2.3", "v3.0.0-rc.1", "", "3.0.0-rc.1"},
+	}
+	for _, c := range cases {
+		next, err := BumpVersion(c.current, c.bump, c.preid)
+		assert.NoError(t, err, c.current+" "+c.bump)
+		assert.Equal(t, c.next, next, c.current+" "+c.bump)
+	}
+
+	_, err := BumpVersion("1.2.3", "1.2.3", "")
+	assert.Error(t, err)
+	_, err = BumpVersion("1.2.3", "huge", "")
+	assert.Error(t, err)
+	_, err = BumpVersion("1.2.3", "prerelease", "be ta")
+	assert.Error(t, err)
+}
+
+// Creates a repository with a package in pkg/ committed and HOME pointing to an empty directory
+func createVersionRepo(t *testing.T) (string, *git.Repository, func()) {
+	dir, err := ioutil.TempDir("", "wio-version")
+	assert.NoError(t, err)
+	home := filepath.Join(dir, "home")
+	assert.NoError(t, os.MkdirAll(home, os.ModePerm))
+	oldHome, oldCache := os.Getenv("HOME"), homedir.DisableCache
+	os.Setenv("HOME", home)
+	homedir.DisableCache = true
+
+	repo, err := git.PlainInit(filepath.Join(dir, "repo"), false)
+	assert.NoError(t, err)
+	pkg := filepath.Join(dir, "repo", "pkg")
+	assert.NoError(t, os.MkdirAll(pkg, os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(sys.Path(pkg, sys.Config), []byte("version: 1.0.0\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(pkg, "README.md"), []byte("readme\n"), 0644))
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	_, err = worktree.Add(".")
+	assert.NoError(t, err)
+	signature := &object.Signature{Name: "wio", Email: "wio@example.com", When: time.Now()}
+	_, err = worktree.Commit("initial", &git.CommitOptions{Author: signature})
+	assert.NoError(t, err)
+
+	return pkg, repo, func() {
+		os.Setenv("HOME", oldHome)
+		homedir.DisableCache = oldCache
+		os.RemoveAll(dir)
+	}
+}
+
+// Checks a repository and returns the error it fails with
+func checkRepositoryError(repo *git.Repository, tag string) error {
+	_, err := CheckRepository(repo, tag)
+	return err
+}
+
+func TestCheckRepository(t *testing.T) {
+	pkg, repo, cleanup := createVersionRepo(t)
+	defer cleanup()
+
+	// the version cannot be committed without a signature
+	assert.EqualError(t, checkRepositoryError(repo, "v1.0.1"),
+		"git user.name and user.email have to be set to commit the version")
+
+	os.Setenv("GIT_AUTHOR_NAME", "Version Bot")
+	os.Setenv("GIT_AUTHOR_EMAIL", "bot@example.com")
+	defer os.Unsetenv("GIT_AUTHOR_NAME")
+	defer os.Unsetenv("GIT_AUTHOR_EMAIL")
+	signature, err := CheckRepository(repo, "v1.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Version Bot", signature.Name)
+
+	// untracked files are not uncommitted changes
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(pkg, "notes.txt"), []byte("notes\n"), 0644))
+	assert.NoError(t, checkRepositoryError(repo, "v1.0.1"))
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(pkg, "README.md"), []byte("changed\n"), 0644))
+	assert.EqualError(t, checkRepositoryError(repo, "v1.0.1"),
+		"git working tree has uncommitted changes: pkg/README.md")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	_, err = worktree.Add("pkg/notes.txt")
+	assert.NoError(t, err)
+	assert.EqualError(t, checkRepositoryError(repo, "v1.0.1"),
+		"git working tree has uncommitted changes: pkg/README.md, pkg/notes.txt")
+
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	_, err = repo.CreateTag("v1.0.0", head.Hash(), nil)
+	assert.NoError(t, err)
+	assert.EqualError(t, checkRepositoryError(repo, "v1.0.0"), "git tag v1.0.0 already exists")
+}
+
+func TestVersionTag(t *testing.T) {
+	pkg, repo, cleanup := createVersionRepo(t)
+	defer cleanup()
+
+	// packages in a folder of the repository can share it with other packages
+	tag, err := VersionTag(repo, pkg, "pkg", "1.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "pkg@v1.0.1", tag)
+
+	tag, err = VersionTag(repo, filepath.Dir(pkg), "pkg", "1.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0.1", tag)
+}
+
+func TestCommitVersion(t *testing.T) {
+	pkg, repo, cleanup := createVersionRepo(t)
+	defer cleanup()
+	os.Setenv("GIT_AUTHOR_NAME", "Version Bot")
+	os.Setenv("GIT_AUTHOR_EMAIL", "bot@example.com")
+	defer os.Unsetenv("GIT_AUTHOR_NAME")
+	defer os.Unsetenv("GIT_AUTHOR_EMAIL")
+
+	signature, err := CheckRepository(repo, "v1.0.1")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(sys.Path(pkg, sys.Config), []byte("version: 1.0.1\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(pkg, "notes.txt"), []byte("notes\n"), 0644))
+	assert.NoError(t, CommitVersion(repo, pkg, "v1.0.1", "v1.0.1", signature))
+
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	commit, err := repo.CommitObject(head.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0.1", commit.Message)
+	assert.Equal(t, "Version Bot", commit.Author.Name)
+	assert.Equal(t, "bot@example.com", commit.Author.Email)
+
+	// only wio.yml is committed
+	stats, err := commit.Stats()
+	assert.NoError(t, err)
+	assert.Len(t, stats, 1)
+	assert.Equal(t, "pkg/wio.yml", stats[0].Name)
+
+	tagRef, err := repo.Tag("v1.0.1")
+	assert.NoError(t, err)
+	tag, err := repo.TagObject(tagRef.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, head.Hash(), tag.Target)
+	assert.Equal(t, "Version Bot", tag.Tagger.Name)
+	assert.NoError(t, checkRepositoryError(repo, "v1.0.2"))
+}
+
+func TestGitSignature(t *testing.T) {
+	_, repo, cleanup := createVersionRepo(t)
+	defer cleanup()
+
+	_, err := gitSignature(repo)
+	assert.EqualError(t, err, "git user.name and user.email have to be set to commit the version")
+
+	// global config is used when the repository does not set the user
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(os.Getenv("HOME"), ".gitconfig"),
+		[]byte("[user]\n\tname = Global\n\temail = global@example.com\n"), 0644))
+	signature, err := gitSignature(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, "Global", signature.Name)
+	assert.Equal(t, "global@example.com", signature.Email)
+
+	cfg, err := repo.Config()
+	assert.NoError(t, err)
+	cfg.Raw.Section("user").SetOption("name", "Local")
+	assert.NoError(t, repo.Storer.SetConfig(cfg))
+	signature, err = gitSignature(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, "Local", signature.Name)
+	assert.Equal(t, "global@example.com", signature.Email)
+
+	os.Setenv("GIT_AUTHOR_EMAIL", "env@example.com")
+	defer os.Unsetenv("GIT_AUTHOR_EMAIL")
+	signature, err = gitSignature(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, "Local", signature.Name)
+	assert.Equal(t, "env@example.com", signature.Email)
+}
@@ -1,5 +1,16 @@
 package registry
 
+import "wio/internal/env"
+
 const (
 	WioPackageRegistry = "https://registry.npmjs.org"
 )
+
+// Registry packages are fetched from and published to. WIOREGISTRY can be set with wio env to use
+// another npm registry like a local Verdaccio
+func Url() string {
+	if url := env.GetRegistry(); url != "" {
+		return url
+	}
+	return WioPackageRegistry
+}
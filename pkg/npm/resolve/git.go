@@ -0,0 +1,211 @@
+package resolve
+
+import (
+	"os"
+	"regexp"
+	"wio/internal/types"
+	"wio/pkg/log"
+	"wio/pkg/npm"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+var commitHash = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// Uses a package from a git repository. A branch or tag is resolved to a commit which is recorded
+// in wio.lock and used from then on until the dependency is updated with wio install --update
+func (i *Info) gitResolve(name string, dep types.Dependency, root *Node, install bool) error {
+	given := 0
+	for _, value := range []string{dep.GetRef(), dep.GetTag(), dep.GetCommit()} {
+		if value != "" {
+			given++
+		}
+	}
+	if given > 1 {
+		return util.Error("%s dependency can only have one of ref, tag and commit", name)
+	}
+	if dep.GetCommit() != "" && !commitHash.MatchString(dep.GetCommit()) {
+		return util.Error("%s dependency commit %s is not a full commit hash", name, dep.GetCommit())
+	}
+
+	dst := sys.Path(i.dir, sys.WioFolder, sys.Modules, sys.Git, name)
+	commit := dep.GetCommit()
+	if commit == "" && !i.update[name] {
+		commit = i.lock.GitCommit(name, dep)
+	}
+
+	if install {
+		resolved, err := fetchGit(name, dep, commit, dst)
+		if err != nil {
+			return err
+		}
+		if resolved != i.lock.GitCommit(name, dep) {
+			i.lock.SetGitCommit(name, dep, resolved)
+			i.lockChanged = true
+		}
+	} else if head := gitHead(dst); head == "" {
+		return util.Error("%s dependency is not installed. Check wio install", name)
+	} else if commit != "" && head != commit {
+		return util.Error("%s dependency is at commit %s instead of %s. Check wio install", name, head, commit)
+	}
+
+	config, version, err := checkPackage(name, dep, dst)
+	if err != nil {
+		return err
+	}
+	i.SetPkg(name, version.String(), &Package{
+		Vendor: true,
+		Path:   dst,
+		Config: config,
+		Version: &npm.Version{
+			Name:         config.GetName(),
+			Version:      config.GetVersion(),
+			Dependencies: config.DependencyMap(),
+		},
+	})
+	node := &Node{Name: name, ConfigVersion: version.String(), Vendor: true}
+	root.Dependencies = append(root.Dependencies, node)
+	return nil
+}
+
+// Returns the commit checked out in a clone or an empty string if there is no clone
+func gitHead(dst string) string {
+	repo, err := git.PlainOpen(dst)
+	if err != nil {
+		return ""
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return ""
+	}
+	return head.Hash().String()
+}
+
+// Clones or fetches the repository of a git dependency into dst and checks out the commit. When
+// no commit is given, the ref or tag of the dependency is looked up in the repository. The commit
+// that was checked out is returned
+func fetchGit(name string, dep types.Dependency, commit string, dst string) (string, error) {
+	if commit != "" && gitHead(dst) == commit {
+		return commit, nil
+	}
+
+	log.Info(log.Cyan, "Fetching ")
+	log.Info(log.Green, "%s ", name)
+	log.Info(log.Cyan, "from ")
+	log.Info(log.Green, "%s ", dep.GetGit())
+	log.Info(log.Cyan, "... ")
+
+	resolved, err := checkoutGit(dep, commit, dst)
+	if err != nil {
+		log.WriteFailure()
+		return "", util.Error("%s dependency could not be fetched from %s: %s", name, dep.GetGit(), err.Error())
+	}
+	log.WriteSuccess()
+	return resolved, nil
+}
+
+func checkoutGit(dep types.Dependency, commit string, dst string) (string, error) {
+	repo, err := git.PlainOpen(dst)
+	if err == git.ErrRepositoryNotExists {
+		if err := os.MkdirAll(dst, os.ModePerm); err != nil {
+			return "", err
+		}
+		repo, err = git.PlainClone(dst, false, &git.CloneOptions{URL: dep.GetGit(), NoCheckout: true,
+			Tags: git.AllTags})
+		if err != nil {
+			os.RemoveAll(dst)
+			return "", err
+		}
+	} else if err != nil {
+		return "", err
+	} else if commit == "" || !hasCommit(repo, commit) {
+		err := repo.Fetch(&git.FetchOptions{
+			RefSpecs: []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*"},
+			Tags:     git.AllTags,
+		})
+		if err != nil && err != git.NoErrAlreadyUpToDate {
+			return "", err
+		}
+	}
+
+	if commit == "" {
+		if commit, err = remoteCommit(repo, dep); err != nil {
+			return "", err
+		}
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(commit))
+	if err != nil {
+		return "", util.Error("commit %s does not exist", commit)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: *hash, Force: true}); err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+func hasCommit(repo *git.Repository, commit string) bool {
+	_, err := repo.CommitObject(plumbing.NewHash(commit))
+	return err == nil
+}
+
+// Looks up the commit a ref or tag points to in the remote repository. Without either, the
+// default branch is used. Annotated tags are peeled to the commit they point to
+func remoteCommit(repo *git.Repository, dep types.Dependency) (string, error) {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return "", err
+	}
+	refs, err := remote.List(&git.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	want := plumbing.HEAD
+	if dep.GetTag() != "" {
+		want = plumbing.NewTagReferenceName(dep.GetTag())
+	} else if dep.GetRef() != "" {
+		want = plumbing.NewBranchReferenceName(dep.GetRef())
+	}
+
+	for tries := 0; tries < 2; tries++ {
+		for _, ref := range refs {
+			if ref.Name() != want {
+				continue
+			} else if ref.Type() == plumbing.SymbolicReference {
+				want = ref.Target()
+				break
+			}
+			return peelTag(repo, ref.Hash())
+		}
+	}
+	if dep.GetTag() != "" {
+		return "", util.Error("tag %s does not exist", dep.GetTag())
+	} else if dep.GetRef() != "" {
+		return "", util.Error("branch %s does not exist", dep.GetRef())
+	}
+	return "", util.Error("repository does not have a default branch")
+}
+
+// Annotated tags are objects of their own which point to the commit. Other hashes are commits
+func peelTag(repo *git.Repository, hash plumbing.Hash) (string, error) {
+	tag, err := repo.TagObject(hash)
+	if err == plumbing.ErrObjectNotFound {
+		return hash.String(), nil
+	} else if err != nil {
+		return "", err
+	}
+	commit, err := tag.Commit()
+	if err != nil {
+		return "", util.Error("tag %s does not point to a commit", tag.Name)
+	}
+	return commit.Hash.String(), nil
+}
@@ -0,0 +1,103 @@
+package resolve
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+	"wio/internal/types"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const gitTestConfig = `type: pkg
+project:
+  name: lib
+  version: %s
+  compile_options:
+    wio_version: 0.10.0
+`
+
+// Creates a bare repository with a commit tagged v1.0.0 by an annotated tag and a newer commit
+// on master. Returns the path of the repository and the tagged and the newer commit
+func createGitTestRepo(t *testing.T, dir string) (string, string, string) {
+	src, bare := filepath.Join(dir, "src"), filepath.Join(dir, "lib.git")
+	repo, err := git.PlainInit(src, false)
+	assert.NoError(t, err)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	signature := &object.Signature{Name: "wio", Email: "wio@example.com", When: time.Now()}
+
+	commit := func(version string) plumbing.Hash {
+		text := []byte(fmt.Sprintf(gitTestConfig, version))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "wio.yml"), text, 0644))
+		_, err := worktree.Add("wio.yml")
+		assert.NoError(t, err)
+		hash, err := worktree.Commit(version, &git.CommitOptions{Author: signature})
+		assert.NoError(t, err)
+		return hash
+	}
+
+	tagged := commit("1.0.0")
+	tag, err := repo.CreateTag("v1.0.0", tagged, &git.CreateTagOptions{Tagger: signature, Message: "v1.0.0"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, tagged, tag.Hash())
+	newer := commit("1.1.0")
+
+	_, err = git.PlainClone(bare, true, &git.CloneOptions{URL: src, Tags: git.AllTags})
+	assert.NoError(t, err)
+	return bare, tagged.String(), newer.String()
+}
+
+func TestGitResolve(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is needed to clone local repositories")
+	}
+	dir, err := ioutil.TempDir("", "wio-git")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	url, tagged, newer := createGitTestRepo(t, dir)
+
+	project := filepath.Join(dir, "project")
+	assert.NoError(t, os.MkdirAll(project, os.ModePerm))
+	tagDep := &types.DependencyImpl{Git: url, Tag: "v1.0.0", Version: "^1.0.0"}
+	branchDep := &types.DependencyImpl{Git: url, Ref: "master", Version: "^1.0.0"}
+
+	// annotated tags lock the commit they point to, not the tag object
+	info := NewInfo(project)
+	assert.NoError(t, info.gitResolve("lib", tagDep, &Node{}, true))
+	assert.Equal(t, tagged, info.lock.GitCommit("lib", tagDep))
+	clone := sys.Path(project, sys.WioFolder, sys.Modules, sys.Git, "lib")
+	assert.Equal(t, tagged, gitHead(clone))
+	assert.True(t, info.lockChanged)
+
+	repo, err := git.PlainOpen(clone)
+	assert.NoError(t, err)
+	commit, err := remoteCommit(repo, tagDep)
+	assert.NoError(t, err)
+	assert.Equal(t, tagged, commit)
+
+	// the lock survives being written and read and matches the checkout
+	assert.NoError(t, types.WriteLock(project, info.lock))
+	lock, err := types.ReadLock(project)
+	assert.NoError(t, err)
+	assert.Equal(t, tagged, lock.GitCommit("lib", tagDep))
+
+	info = NewInfo(project)
+	info.lock = lock
+	assert.NoError(t, info.gitResolve("lib", tagDep, &Node{}, false))
+	assert.NoError(t, info.gitResolve("lib", tagDep, &Node{}, true))
+	assert.False(t, info.lockChanged)
+
+	// asking for a branch instead changes what is locked
+	assert.Equal(t, "", lock.GitCommit("lib", branchDep))
+	assert.NoError(t, info.gitResolve("lib", branchDep, &Node{}, true))
+	assert.Equal(t, newer, info.lock.GitCommit("lib", branchDep))
+}
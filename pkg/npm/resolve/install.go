@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"wio/pkg/npm"
 	"wio/pkg/npm/publish"
 	"wio/pkg/util"
@@ -43,26 +44,37 @@ func (i *Info) install(name, ver string, data *npm.Version) error {
 	tar := sys.Path(i.dir, sys.WioFolder, sys.Cache, file+".tgz")
 	if !sys.Exists(tar) {
 		url := data.Dist.Tarball
-		total, err := contentSize(url)
-		if err != nil {
-			return err
-		}
-		cb := &counter{total: total, cb: installCallback(name, ver)}
-		if err := download(url, tar, cb); err != nil {
-			return err
+		if i.isMirrorTarball(url) {
+			// tarballs of a mirror directory are copied
+			if err := os.MkdirAll(filepath.Dir(tar), os.ModePerm); err != nil {
+				return err
+			}
+			if err := util.CopyFile(url, tar); err != nil {
+				return err
+			}
+		} else if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return util.Error("%s@%s has tarball %s which is not an http url", name, ver, url)
+		} else {
+			total, err := contentSize(url)
+			if err != nil {
+				return err
+			}
+			cb := &counter{total: total, cb: installCallback(name, ver)}
+			if err := download(url, tar, cb); err != nil {
+				return err
+			}
 		}
 	}
 
-	// check shashum
 	tarData, err := ioutil.ReadFile(tar)
 	if err != nil {
 		return err
 	}
-	if sha := publish.Shasum(tarData); sha != data.Dist.Shasum {
+	if err := checkTarball(tarData, data.Dist); err != nil {
 		if err := os.RemoveAll(tar); err != nil {
 			return err
 		}
-		return util.Error("expected tar checksum %s", data.Dist.Shasum)
+		return util.Error("%s@%s: %s", name, ver, err.Error())
 	}
 
 	modules := sys.Path(i.dir, sys.WioFolder, sys.Modules)
@@ -104,6 +116,20 @@ func download(url string, dst string, cb io.Writer) error {
 	return os.Rename(dst+sys.TempFolder, dst)
 }
 
+// Checks a tarball against the integrity and shasum the registry has for it
+func checkTarball(tarData []byte, dist npm.Dist) error {
+	if dist.Integrity == "" && dist.Shasum == "" {
+		return util.Error("registry has no checksum for the tarball")
+	}
+	if dist.Integrity != "" && !publish.CheckIntegrity(tarData, dist.Integrity) {
+		return util.Error("tarball does not match integrity %s", dist.Integrity)
+	}
+	if dist.Shasum != "" && publish.Shasum(tarData) != dist.Shasum {
+		return util.Error("expected tar checksum %s", dist.Shasum)
+	}
+	return nil
+}
+
 func untar(src string, dest string) error {
 	return archiver.Unarchive(src, dest)
 }
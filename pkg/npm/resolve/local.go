@@ -39,6 +39,32 @@ func findLocalConfigs(root string) ([]string, error) {
 	return ret, nil
 }
 
+// Finds other members of the workspace root is part of
+func findWorkspaceConfigs(root string) ([]*types.WorkspaceMember, error) {
+	workspace, err := types.FindWorkspace(root)
+	if err != nil || workspace == "" {
+		return nil, err
+	}
+	members, err := types.ReadWorkspace(workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*types.WorkspaceMember
+	for _, member := range members {
+		if !sameDir(member.Path, root) && member.Config.GetType() == constants.Pkg {
+			ret = append(ret, member)
+		}
+	}
+	return ret, nil
+}
+
+func sameDir(a string, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && a == b
+}
+
 func tryFindConfig(name, ver, path string, strict bool) (types.Config, error) {
 	config, err := tryGetConfig(path)
 	if err != nil {
@@ -0,0 +1,140 @@
+package resolve
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"wio/pkg/npm"
+	"wio/pkg/npm/client"
+	"wio/pkg/npm/publish"
+	"wio/pkg/npm/semver"
+	"wio/pkg/util"
+	"wio/pkg/util/sys"
+)
+
+// Fetches packages from a mirror instead of the registry. A mirror is the url of an npm registry
+// or a directory of tarballs named <name>-<version>.tgz like npm pack names them, which lets
+// machines without internet access install packages
+func (i *Info) SetMirror(mirror string) {
+	if strings.HasPrefix(mirror, "http://") || strings.HasPrefix(mirror, "https://") {
+		i.fetch = func(name string) (*npm.Data, error) {
+			return client.FetchPackageDataFrom(mirror, name)
+		}
+	} else {
+		i.fetch = func(name string) (*npm.Data, error) {
+			return localPackageData(mirror, name)
+		}
+		if dir, err := filepath.Abs(mirror); err == nil {
+			i.mirrorDir = dir
+		}
+	}
+	i.fetchVersion = func(name string, ver string) (*npm.Version, error) {
+		data, err := i.GetData(name)
+		if err != nil {
+			return nil, err
+		}
+		if version, exists := data.Versions[ver]; exists {
+			return &version, nil
+		}
+		return nil, util.Error("%s@%s is not in mirror %s", name, ver, mirror)
+	}
+}
+
+// Whether a tarball is a file in the mirror directory. Registries only ever give http urls
+func (i *Info) isMirrorTarball(url string) bool {
+	return i.mirrorDir != "" && filepath.Dir(url) == i.mirrorDir
+}
+
+// Builds package data from the tarballs of a package in a directory. Tarballs are checked against
+// integrity computed here, so they are only as trustworthy as the directory
+func localPackageData(dir string, name string) (*npm.Data, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	// npm pack names @scope/name tarballs scope-name-<version>.tgz
+	prefix := strings.Replace(strings.TrimPrefix(name, "@"), "/", "-", -1) + "-"
+
+	data := &npm.Data{Name: name, DistTags: map[string]string{}, Versions: map[string]npm.Version{}}
+	var releases semver.List
+	for _, file := range files {
+		fileName := file.Name()
+		if file.IsDir() || !strings.HasPrefix(fileName, prefix) || !strings.HasSuffix(fileName, ".tgz") {
+			continue
+		}
+		version := semver.Parse(strings.TrimSuffix(strings.TrimPrefix(fileName, prefix), ".tgz"))
+		if version == nil {
+			continue
+		}
+
+		tarball, err := filepath.Abs(sys.Path(dir, fileName))
+		if err != nil {
+			return nil, err
+		}
+		contents, err := ioutil.ReadFile(tarball)
+		if err != nil {
+			return nil, err
+		}
+		versionData, err := tarballPackageJson(tarball)
+		if err != nil {
+			return nil, util.Error("%s: %s", tarball, err.Error())
+		}
+		if versionData.Name != name || versionData.Version != version.String() {
+			return nil, util.Error("%s contains %s@%s", tarball, versionData.Name, versionData.Version)
+		}
+		versionData.Dist = npm.Dist{
+			Tarball:   tarball,
+			Shasum:    publish.Shasum(contents),
+			Integrity: publish.Integrity(contents),
+		}
+		data.Versions[version.String()] = *versionData
+		if len(version.Pre) <= 0 {
+			releases = append(releases, version)
+		}
+	}
+
+	if len(data.Versions) <= 0 {
+		return nil, util.Error("package not found in %s: %s", dir, name)
+	}
+	releases.Sort()
+	if len(releases) > 0 {
+		data.DistTags[Latest] = releases.Last().String()
+	}
+	return data, nil
+}
+
+// Reads package.json out of a package tarball
+func tarballPackageJson(tarball string) (*npm.Version, error) {
+	file, err := os.Open(tarball)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, util.Error("package.json not found")
+		} else if err != nil {
+			return nil, err
+		}
+		// contents are in a single top level folder, usually package/
+		parts := strings.SplitN(path.Clean(header.Name), "/", 2)
+		if len(parts) == 2 && parts[1] == "package.json" {
+			ret := &npm.Version{}
+			return ret, json.NewDecoder(reader).Decode(ret)
+		}
+	}
+}
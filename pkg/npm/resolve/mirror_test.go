@@ -0,0 +1,102 @@
+package resolve
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"wio/pkg/npm/publish"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTarball(t *testing.T, path string, name string, version string) {
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	defer gz.Close()
+	writer := tar.NewWriter(gz)
+	defer writer.Close()
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"name":         name,
+		"version":      version,
+		"dependencies": map[string]string{"avr-gcc": "^5.0.0"},
+	})
+	assert.NoError(t, writer.WriteHeader(&tar.Header{Name: "package/package.json", Mode: 0644,
+		Size: int64(len(data))}))
+	_, err = writer.Write(data)
+	assert.NoError(t, err)
+}
+
+func TestLocalPackageData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-mirror")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTarball(t, filepath.Join(dir, "wio-framework-avr-cosa-1.0.0.tgz"), "wio-framework-avr-cosa", "1.0.0")
+	writeTarball(t, filepath.Join(dir, "wio-framework-avr-cosa-1.2.0.tgz"), "wio-framework-avr-cosa", "1.2.0")
+	writeTarball(t, filepath.Join(dir, "wio-framework-avr-cosa-2.0.0-rc.1.tgz"), "wio-framework-avr-cosa",
+		"2.0.0-rc.1")
+	writeTarball(t, filepath.Join(dir, "wio-framework-avr-cosa-extra-1.0.0.tgz"), "wio-framework-avr-cosa-extra",
+		"1.0.0")
+
+	data, err := localPackageData(dir, "wio-framework-avr-cosa")
+	assert.NoError(t, err)
+	assert.Len(t, data.Versions, 3)
+	assert.Equal(t, "1.2.0", data.DistTags[Latest])
+
+	version := data.Versions["1.0.0"]
+	assert.Equal(t, map[string]string{"avr-gcc": "^5.0.0"}, version.Dependencies)
+	contents, err := ioutil.ReadFile(version.Dist.Tarball)
+	assert.NoError(t, err)
+	assert.True(t, publish.CheckIntegrity(contents, version.Dist.Integrity))
+
+	_, err = localPackageData(dir, "wio-framework-avr-arduino")
+	assert.Error(t, err)
+
+	// tarballs have to contain the version they are named after
+	writeTarball(t, filepath.Join(dir, "wio-framework-avr-cosa-3.0.0.tgz"), "wio-framework-avr-cosa", "1.0.0")
+	_, err = localPackageData(dir, "wio-framework-avr-cosa")
+	assert.Error(t, err)
+}
+
+func TestIsMirrorTarball(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-mirror")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tarball := filepath.Join(dir, "avr-gcc-5.4.0.tgz")
+
+	// tarballs from registries are never copied from disk
+	info := NewInfo(dir)
+	assert.False(t, info.isMirrorTarball(tarball))
+	info.SetMirror("http://localhost:4873")
+	assert.False(t, info.isMirrorTarball(tarball))
+
+	info = NewInfo(dir)
+	info.SetMirror(dir)
+	assert.True(t, info.isMirrorTarball(tarball))
+	assert.False(t, info.isMirrorTarball(filepath.Join(filepath.Dir(dir), "avr-gcc-5.4.0.tgz")))
+	assert.False(t, info.isMirrorTarball("/etc/passwd"))
+}
+
+func TestMirrorVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-mirror")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeTarball(t, filepath.Join(dir, "avr-gcc-5.4.0.tgz"), "avr-gcc", "5.4.0")
+
+	// exact versions come from the mirror too instead of the registry
+	info := NewInfo(dir)
+	info.SetMirror(dir)
+	version, err := info.GetVersion("avr-gcc", "5.4.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "5.4.0", version.Version)
+
+	_, err = info.GetVersion("avr-gcc", "5.9.0")
+	assert.Error(t, err)
+}
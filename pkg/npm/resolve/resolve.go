@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"github.com/hashicorp/go-getter"
 	"os"
+	"path/filepath"
 	"strings"
 	"wio/internal/constants"
 	"wio/internal/types"
 	"wio/pkg/log"
+	"wio/pkg/npm"
 	"wio/pkg/npm/semver"
 	"wio/pkg/util"
 	"wio/pkg/util/sys"
@@ -118,13 +120,67 @@ func (i *Info) customUrlResolve(name string, dep types.Dependency, customPath st
 			Version: nil,
 		})
 
-		return i.resolveRemote(config, node, install)
+		return i.resolveRemote(config, node, dst, install)
 	}
 
 	return nil
 }
 
-func (i *Info) createNodesAndFetch(deps map[string]types.Dependency, root *Node, install bool) error {
+// Uses a package from a directory on disk in place. Relative paths are relative to the project
+// that depends on the package and the version required has to match the version of the package
+func (i *Info) pathResolve(name string, dep types.Dependency, dir string, root *Node) error {
+	path := dep.GetPath()
+	if !filepath.IsAbs(path) {
+		path = sys.Path(dir, path)
+	}
+	path = filepath.Clean(path)
+
+	config, version, err := checkPackage(name, dep, path)
+	if err != nil {
+		return err
+	}
+
+	i.SetPkg(name, version.String(), &Package{
+		Vendor: true,
+		Path:   path,
+		Config: config,
+		Version: &npm.Version{
+			Name:         config.GetName(),
+			Version:      config.GetVersion(),
+			Dependencies: config.DependencyMap(),
+		},
+	})
+	node := &Node{Name: name, ConfigVersion: version.String(), Vendor: true, Linked: true}
+	root.Dependencies = append(root.Dependencies, node)
+	return nil
+}
+
+// Reads the package in path and checks that it is the package and version a dependency requires
+func checkPackage(name string, dep types.Dependency, path string) (types.Config, *s.Version, error) {
+	config, err := tryGetConfig(path)
+	if err != nil {
+		return nil, nil, err
+	} else if config == nil {
+		return nil, nil, util.Error("%s dependency path %s does not contain a wio.yml", name, path)
+	}
+	if config.GetName() != name {
+		return nil, nil, util.Error("%s dependency path %s contains package %s", name, path, config.GetName())
+	}
+
+	version := semver.Parse(config.GetVersion())
+	if version == nil {
+		return nil, nil, util.Error("%s dependency cannot have invalid version: %s", name, config.GetVersion())
+	}
+	if query := semver.MakeQuery(dep.GetVersion()); query == nil {
+		return nil, nil, util.Error("%s dependency version %s specified is not valid", name, dep.GetVersion())
+	} else if !query.Matches(version) {
+		return nil, nil, util.Error("%s@%s at %s does not match required version %s", name,
+			config.GetVersion(), path, dep.GetVersion())
+	}
+	return config, version, nil
+}
+
+func (i *Info) createNodesAndFetch(deps map[string]types.Dependency, root *Node, dir string, install bool) error {
 	customPath := sys.Path(i.dir, sys.WioFolder, sys.Modules, sys.Custom)
 	if err := os.MkdirAll(customPath, os.ModePerm); err != nil {
 		return err
@@ -135,8 +191,22 @@ func (i *Info) createNodesAndFetch(deps map[string]types.Dependency, root *Node,
 			return util.Error("%s dependency cannot be empty", name)
 		}
 
-		// custom url is not provided
-		if dep.GetUrl() == nil {
+		if dep.GetPath() != "" {
+			if err := i.pathResolve(name, dep, dir, root); err != nil {
+				return err
+			}
+		} else if dep.GetGit() != "" {
+			if err := i.gitResolve(name, dep, root, install); err != nil {
+				return err
+			}
+		} else if version, exists := i.members[name]; exists && dep.GetUrl() == nil {
+			if query := semver.MakeQuery(dep.GetVersion()); query != nil && !query.Matches(semver.Parse(version)) {
+				return util.Error("%s@%s is required but workspace has %s@%s", name, dep.GetVersion(), name, version)
+			}
+			node := &Node{Name: name, ConfigVersion: version, Vendor: true, Linked: true}
+			root.Dependencies = append(root.Dependencies, node)
+		} else if dep.GetUrl() == nil {
+			// custom url is not provided
 			node := &Node{Name: name, ConfigVersion: dep.GetVersion(), Vendor: dep.IsVendor()}
 			root.Dependencies = append(root.Dependencies, node)
 		} else {
@@ -149,8 +219,8 @@ func (i *Info) createNodesAndFetch(deps map[string]types.Dependency, root *Node,
 	return nil
 }
 
-func (i *Info) resolveRemote(config types.Config, root *Node, install bool) error {
-	if err := i.createNodesAndFetch(config.GetDependencies(), root, install); err != nil {
+func (i *Info) resolveRemote(config types.Config, root *Node, dir string, install bool) error {
+	if err := i.createNodesAndFetch(config.GetDependencies(), root, dir, install); err != nil {
 		return err
 	}
 
@@ -166,7 +236,9 @@ func (i *Info) resolveRemote(config types.Config, root *Node, install bool) erro
 }
 
 func (i *Info) ResolveRemote(config types.Config, install bool) error {
-	logResolveStart(config)
+	if !i.quiet {
+		logResolveStart(config)
+	}
 
 	if err := i.LoadLocal(); err != nil {
 		return err
@@ -189,16 +261,25 @@ func (i *Info) ResolveRemote(config types.Config, install bool) error {
 		})
 	}
 
-	if err := i.resolveRemote(config, i.root, install); err != nil {
+	if err := i.resolveRemote(config, i.root, i.dir, install); err != nil {
 		return err
 	}
+	if i.lockChanged {
+		if err := types.WriteLock(i.dir, i.lock); err != nil {
+			return err
+		}
+	}
 
-	logResolveDone(i.root)
+	if !i.quiet {
+		logResolveDone(i.root)
+	}
 	return nil
 }
 
 func (i *Info) ResolveTree(root *Node, install bool) error {
-	logResolve(root)
+	if !i.quiet {
+		logResolve(root)
+	}
 
 	if ret := i.GetRes(root.Name, root.ConfigVersion); ret != nil {
 		root.ResolvedVersion = ret
@@ -216,7 +297,7 @@ func (i *Info) ResolveTree(root *Node, install bool) error {
 		if err != nil {
 			return err
 		}
-		if err := i.createNodesAndFetch(pkg.Config.GetDependencies(), root, install); err != nil {
+		if err := i.createNodesAndFetch(pkg.Config.GetDependencies(), root, pkg.Path, install); err != nil {
 			return err
 		}
 	} else {
@@ -224,6 +305,9 @@ func (i *Info) ResolveTree(root *Node, install bool) error {
 		if err != nil {
 			return err
 		}
+		if install && data.Deprecated != "" {
+			log.Warnln("%s@%s is deprecated: %s", root.Name, ver.String(), data.Deprecated)
+		}
 		for name, ver := range data.Dependencies {
 			node := &Node{Name: name, ConfigVersion: ver, Vendor: false}
 			root.Dependencies = append(root.Dependencies, node)
@@ -250,6 +334,11 @@ func (i *Info) resolveVer(name string, ver string) (*s.Version, error) {
 	if query == nil {
 		return nil, util.Error("invalid version expression %s", ver)
 	}
+	if locked := semver.Parse(i.lock.ToolchainVersion(name, ver)); i.toolchains && locked != nil &&
+		query.Matches(locked) {
+		i.StoreVer(name, locked)
+		return locked, nil
+	}
 	if ret := i.resolve[name].Find(query); ret != nil {
 		return ret, nil
 	}
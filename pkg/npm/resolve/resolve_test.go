@@ -0,0 +1,143 @@
+package resolve
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"wio/internal/types"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const pathTestConfig = `type: pkg
+project:
+  name: %s
+  version: %s
+  compile_options:
+    wio_version: 0.10.0
+%s`
+
+func writePathTestPackage(t *testing.T, dir, name, version, dependencies string) {
+	assert.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	text := []byte(fmt.Sprintf(pathTestConfig, name, version, dependencies))
+	assert.NoError(t, ioutil.WriteFile(sys.Path(dir, sys.Config), text, 0644))
+}
+
+func TestCheckPackage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-path")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writePathTestPackage(t, sys.Path(dir, "foo"), "foo", "1.2.0", "")
+	writePathTestPackage(t, sys.Path(dir, "bad"), "bad", "latest", "")
+
+	tests := []struct {
+		name    string
+		path    string
+		version string
+		err     string
+	}{
+		{"foo", "foo", "^1.0.0", ""},
+		{"foo", "foo", "1.2.0", ""},
+		{"foo", "foo", "~1.1.0", "foo@1.2.0 at %s does not match required version ~1.1.0"},
+		{"foo", "foo", "one", "foo dependency version one specified is not valid"},
+		{"bar", "foo", "^1.0.0", "bar dependency path %s contains package foo"},
+		{"foo", "none", "^1.0.0", "foo dependency path %s does not contain a wio.yml"},
+		{"bad", "bad", "*", "bad dependency cannot have invalid version: latest"},
+	}
+	for _, test := range tests {
+		path := sys.Path(dir, test.path)
+		config, version, err := checkPackage(test.name, &types.DependencyImpl{Version: test.version}, path)
+		if test.err != "" {
+			if strings.Contains(test.err, "%s") {
+				test.err = fmt.Sprintf(test.err, path)
+			}
+			assert.EqualError(t, err, test.err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.name, config.GetName())
+		assert.Equal(t, "1.2.0", version.String())
+	}
+}
+
+func TestPathResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-path")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	project := sys.Path(dir, "project")
+	writePathTestPackage(t, sys.Path(dir, "bar"), "bar", "2.0.0", "")
+
+	// relative paths are relative to the package that depends on them
+	info := NewInfo(project)
+	root := &Node{}
+	assert.NoError(t, info.pathResolve("bar", &types.DependencyImpl{Path: "../bar", Version: "^2.0.0"}, project, root))
+	assert.Equal(t, 1, len(root.Dependencies))
+	assert.True(t, root.Dependencies[0].Linked)
+	pkg, err := info.GetPkg("bar", "2.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, sys.Path(dir, "bar"), pkg.Path)
+	assert.True(t, root.HasLinked())
+}
+
+func TestResolveLinkedDependencies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-path")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	project := sys.Path(dir, "project")
+	writePathTestPackage(t, project, "app", "0.0.1", "dependencies:\n  foo:\n    version: 1.0.0\n    vendor: true\n")
+	writePathTestPackage(t, sys.Path(dir, "bar"), "bar", "2.0.0", "")
+
+	// a vendored package without path dependencies links nothing
+	foo := sys.Path(project, sys.Vendor, "foo")
+	writePathTestPackage(t, foo, "foo", "1.0.0", "")
+	config, err := types.ReadWioConfig(project, true)
+	assert.NoError(t, err)
+	info := NewInfo(project)
+	info.SetQuiet()
+	assert.NoError(t, info.ResolveRemote(config, false))
+	assert.False(t, info.GetRoot().HasLinked())
+
+	// path dependencies of dependencies are found too
+	writePathTestPackage(t, foo, "foo", "1.0.0", "dependencies:\n  bar:\n    path: ../../../bar\n    version: ^2.0.0\n")
+	info = NewInfo(project)
+	info.SetQuiet()
+	assert.NoError(t, info.ResolveRemote(config, false))
+	assert.False(t, info.GetRoot().Dependencies[0].Linked)
+	assert.True(t, info.GetRoot().HasLinked())
+}
+
+func TestResolveLockedToolchain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wio-mirror")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTarball(t, filepath.Join(dir, "avr-gcc-5.4.0.tgz"), "avr-gcc", "5.4.0")
+	writeTarball(t, filepath.Join(dir, "avr-gcc-5.9.0.tgz"), "avr-gcc", "5.9.0")
+
+	lock := &types.LockImpl{}
+	lock.SetToolchainVersion("avr-gcc", "^5.0.0", "5.4.0")
+	lock.SetToolchainVersion("avr-gcc", "~5.9.0", "5.4.0")
+
+	// ranges resolve to the newest version unless the toolchain lock is used
+	info := NewInfo(dir)
+	info.SetMirror(dir)
+	version, err := info.resolveVer("avr-gcc", "^5.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "5.9.0", version.String())
+
+	info = NewInfo(dir)
+	info.SetMirror(dir)
+	info.SetToolchainLock(lock)
+	version, err = info.resolveVer("avr-gcc", "^5.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "5.4.0", version.String())
+
+	// locked versions that do not match the range anymore are ignored
+	version, err = info.resolveVer("avr-gcc", "~5.9.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "5.9.0", version.String())
+}
@@ -28,6 +28,24 @@ type Info struct {
 	resolve ListMap
 	lists   ListMap
 
+	// versions of workspace members by name
+	members map[string]string
+
+	// commits git dependencies resolved to and git dependencies to fetch again
+	lock        *types.LockImpl
+	lockChanged bool
+	// whether ranges resolve to the toolchain versions locked for them
+	toolchains bool
+	update     map[string]bool
+	// resolves without logging, like when only checking the dependency tree
+	quiet bool
+
+	// fetches package data and data of a version, from the registry unless a mirror is used
+	fetch        func(name string) (*npm.Data, error)
+	fetchVersion func(name string, ver string) (*npm.Version, error)
+	// directory tarballs are copied from when the mirror is a directory
+	mirrorDir string
+
 	root *Node
 }
 
@@ -38,6 +56,8 @@ type Node struct {
 	Dependencies    []*Node
 	Vendor          bool
 	CustomUrl       bool
+	// used in place from a path or a workspace member, so it can change at any time
+	Linked bool
 }
 
 type Package struct {
@@ -47,15 +67,48 @@ type Package struct {
 	Version *npm.Version
 }
 
+// Whether a package in the tree of node is linked
+func (node *Node) HasLinked() bool {
+	for _, dependency := range node.Dependencies {
+		if dependency.Linked || dependency.HasLinked() {
+			return true
+		}
+	}
+	return false
+}
+
 func NewInfo(dir string) *Info {
 	return &Info{
-		dir:     dir,
-		data:    DataCache{},
-		ver:     VerCache{},
-		res:     ResCache{},
-		pkg:     PkgCache{},
-		resolve: ListMap{},
-		lists:   ListMap{},
+		dir:          dir,
+		data:         DataCache{},
+		ver:          VerCache{},
+		res:          ResCache{},
+		pkg:          PkgCache{},
+		resolve:      ListMap{},
+		lists:        ListMap{},
+		members:      map[string]string{},
+		lock:         &types.LockImpl{},
+		update:       map[string]bool{},
+		fetch:        client.FetchPackageData,
+		fetchVersion: client.FetchPackageVersion,
+	}
+}
+
+// Resolves toolchain ranges to the versions locked for them as long as they still match
+func (i *Info) SetToolchainLock(lock *types.LockImpl) {
+	i.lock = lock
+	i.toolchains = true
+}
+
+// Resolves without logging the dependencies resolved and the tree
+func (i *Info) SetQuiet() {
+	i.quiet = true
+}
+
+// Git dependencies with these names are fetched again instead of using the commit in wio.lock
+func (i *Info) SetUpdate(names []string) {
+	for _, name := range names {
+		i.update[name] = true
 	}
 }
 
@@ -112,7 +165,7 @@ func (i *Info) GetData(name string) (*npm.Data, error) {
 	if ret := i.getData(name); ret != nil {
 		return ret, nil
 	}
-	ret, err := client.FetchPackageData(name)
+	ret, err := i.fetch(name)
 	if err != nil {
 		return nil, err
 	}
@@ -138,7 +191,7 @@ func (i *Info) GetVersion(name, ver string) (*npm.Version, error) {
 		return ret, nil
 	}
 
-	ret, err = client.FetchPackageVersion(name, ver)
+	ret, err = i.fetchVersion(name, ver)
 	if err != nil {
 		return nil, err
 	}
@@ -225,6 +278,12 @@ func (i *Info) SetPkg(name, ver string, pkg *Package) {
 }
 
 func (i *Info) LoadLocal() error {
+	lock, err := types.ReadLock(i.dir)
+	if err != nil {
+		return err
+	}
+	i.lock = lock
+
 	paths, err := findLocalConfigs(i.dir)
 	if err != nil {
 		return err
@@ -242,5 +301,25 @@ func (i *Info) LoadLocal() error {
 			return util.Error("package %s missing in lookup", cfg.GetName())
 		}
 	}
+
+	// workspace members are used from where they are like vendored packages
+	members, err := findWorkspaceConfigs(i.dir)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		cfg := member.Config
+		i.members[cfg.GetName()] = cfg.GetVersion()
+		i.SetPkg(cfg.GetName(), cfg.GetVersion(), &Package{
+			Vendor: true,
+			Path:   member.Path,
+			Config: cfg,
+			Version: &npm.Version{
+				Name:         cfg.GetName(),
+				Version:      cfg.GetVersion(),
+				Dependencies: cfg.DependencyMap(),
+			},
+		})
+	}
 	return nil
 }
@@ -0,0 +1,52 @@
+package resolve
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"wio/internal/types"
+	"wio/pkg/util/sys"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeWorkspaceConfig(t *testing.T, dir, text string) {
+	assert.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(sys.Path(dir, sys.Config), []byte(text), 0644))
+}
+
+func resolveWorkspaceApp(t *testing.T, required string) error {
+	dir, err := ioutil.TempDir("", "wio-workspace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, ioutil.WriteFile(sys.Path(dir, sys.Workspace), []byte("members: [app, log]\n"), 0644))
+	writeWorkspaceConfig(t, sys.Path(dir, "log"), `type: pkg
+project:
+  name: log
+  version: 2.0.0
+  compile_options:
+    wio_version: 0.10.0
+`)
+	writeWorkspaceConfig(t, sys.Path(dir, "app"), `type: app
+project:
+  name: app
+  version: 0.0.1
+  compile_options:
+    wio_version: 0.10.0
+dependencies:
+  log:
+    version: `+required+`
+`)
+
+	config, err := types.ReadWioConfig(sys.Path(dir, "app"), true)
+	assert.NoError(t, err)
+	return NewInfo(sys.Path(dir, "app")).ResolveRemote(config, false)
+}
+
+func TestResolveWorkspaceMember(t *testing.T) {
+	assert.NoError(t, resolveWorkspaceApp(t, "^2.0.0"))
+
+	// members that do not match the version required are not linked
+	assert.EqualError(t, resolveWorkspaceApp(t, "^1.0.0"), "log@^1.0.0 is required but workspace has log@2.0.0")
+}
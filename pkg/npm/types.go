@@ -53,6 +53,8 @@ type Version struct {
 	Repository   interface{} `json:"repository"`
 
 	IgnorePaths []string `json:"ignore-files"`
+	Files       []string `json:"files,omitempty"`
+	Deprecated  string   `json:"deprecated,omitempty"`
 }
 
 type Repository struct {
@@ -76,3 +78,20 @@ type License struct {
 	Type    string `json:"type"`
 	Url     string `json:"url"`
 }
+
+type SearchResult struct {
+	Objects []SearchObject `json:"objects"`
+	Total   int            `json:"total"`
+}
+
+type SearchObject struct {
+	Package SearchPackage `json:"package"`
+}
+
+type SearchPackage struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Description string   `json:"description"`
+	Keywords    []string `json:"keywords"`
+	Date        string   `json:"date"`
+}
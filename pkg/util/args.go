@@ -0,0 +1,63 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// Splits a command line into arguments using shell quoting rules. Single quotes keep everything
+// literally, double quotes allow escaping of special characters and a backslash outside of quotes
+// escapes the next character
+func SplitArgs(line string) ([]string, error) {
+	var args []string
+	var current bytes.Buffer
+	var quote rune
+	inArg := false
+	escaped := false
+
+	for _, r := range line {
+		if escaped {
+			// inside double quotes backslash only escapes a few characters
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+			continue
+		}
+
+		switch {
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		return nil, Error("unterminated escape in arguments: %s", line)
+	}
+	if quote != 0 {
+		return nil, Error("unterminated quote in arguments: %s", line)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
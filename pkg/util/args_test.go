@@ -0,0 +1,36 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitArgs(t *testing.T) {
+	args, err := SplitArgs("")
+	assert.Nil(t, err)
+	assert.Empty(t, args)
+
+	args, err = SplitArgs("  one two   three ")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"one", "two", "three"}, args)
+
+	args, err = SplitArgs(`--name "hello world" 'single quoted' ""`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"--name", "hello world", "single quoted", ""}, args)
+
+	args, err = SplitArgs(`a\ b "c \"d\" \n" 'e \ f' g"h"i`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a b", `c "d" \n`, `e \ f`, "ghi"}, args)
+}
+
+func TestSplitArgs_Errors(t *testing.T) {
+	_, err := SplitArgs(`"unterminated`)
+	assert.NotNil(t, err)
+
+	_, err = SplitArgs(`'unterminated`)
+	assert.NotNil(t, err)
+
+	_, err = SplitArgs(`trailing\`)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,124 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContext = 3
+
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// Creates a unified diff between two texts line by line. Empty string is returned when texts are the same
+func UnifiedDiff(oldName string, newName string, oldText string, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName))
+
+	for start := 0; start < len(lines); {
+		// find next change and the hunk around it
+		change := start
+		for change < len(lines) && lines[change].kind == ' ' {
+			change++
+		}
+		if change >= len(lines) {
+			break
+		}
+		hunkStart := change - diffContext
+		if hunkStart < start {
+			hunkStart = start
+		}
+
+		hunkEnd, unchanged := change, 0
+		for hunkEnd < len(lines) && unchanged < 2*diffContext {
+			if lines[hunkEnd].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+			hunkEnd++
+		}
+		if unchanged > diffContext {
+			hunkEnd -= unchanged - diffContext
+		}
+
+		oldStart, newStart := 1, 1
+		for _, line := range lines[:hunkStart] {
+			if line.kind != '+' {
+				oldStart++
+			}
+			if line.kind != '-' {
+				newStart++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, line := range lines[hunkStart:hunkEnd] {
+			if line.kind != '+' {
+				oldCount++
+			}
+			if line.kind != '-' {
+				newCount++
+			}
+		}
+
+		builder.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount))
+		for _, line := range lines[hunkStart:hunkEnd] {
+			builder.WriteByte(line.kind)
+			builder.WriteString(line.text)
+			builder.WriteByte('\n')
+		}
+		start = hunkEnd
+	}
+	return builder.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Finds the longest common subsequence of lines and marks everything else as removed or added
+func diffLines(oldLines []string, newLines []string) []diffLine {
+	common := make([][]int, len(oldLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			lines = append(lines, diffLine{kind: ' ', text: oldLines[i]})
+			i++
+			j++
+		case j >= len(newLines) || (i < len(oldLines) && common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, diffLine{kind: '-', text: oldLines[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{kind: '+', text: newLines[j]})
+			j++
+		}
+	}
+	return lines
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	assert.Equal(t, "", UnifiedDiff("a", "b", "same\n", "same\n"))
+
+	diff := UnifiedDiff("a", "b", "one\ntwo\nthree\n", "one\n2\nthree\nfour\n")
+	assert.Equal(t, "--- a\n+++ b\n@@ -1,3 +1,4 @@\n one\n-two\n+2\n three\n+four\n", diff)
+}
+
+func TestUnifiedDiff_Hunks(t *testing.T) {
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	newText := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n"
+
+	diff := UnifiedDiff("a", "b", oldText, newText)
+	assert.Equal(t, "--- a\n+++ b\n"+
+		"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n"+
+		"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n", diff)
+}
@@ -160,3 +160,32 @@ func CopyDir(src string, dst string) (err error) {
 
 	return
 }
+
+// Total size of files in a directory. Symlinks are not followed
+func DirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// Size in bytes written with a unit
+func FormatSize(size int64) string {
+	switch {
+	case size < 1000:
+		return fmt.Sprintf("%dB", size)
+	case size < 1000*1000:
+		return fmt.Sprintf("%.1fkB", float64(size)/1000)
+	case size < 1000*1000*1000:
+		return fmt.Sprintf("%.1fMB", float64(size)/1000/1000)
+	default:
+		return fmt.Sprintf("%.1fGB", float64(size)/1000/1000/1000)
+	}
+}
@@ -22,9 +22,12 @@ const (
 	WioFolder  = ".wio"
 	TempFolder = ".tmp"
 	Config     = "wio.yml"
+	Workspace  = "wio-workspace.yml"
+	Lock       = "wio.lock"
 	Modules    = "packages"
 	Vendor     = "vendor"
 	Custom     = "custom"
+	Git        = "git"
 	Cache      = "cache"
 	TargetDir  = "targets"
 	BinDir     = "bin"